@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/you/nogodey/internal/syncer"
+)
+
+// runAuditCommand implements `nogodey audit`, which has a judge model
+// review existing translations and lists the errors it found, most severe
+// first.
+func runAuditCommand(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	localeFlag := fs.String("locale", "", "Comma-separated locales to audit (default: the configured locales)")
+	outputFlag := fs.String("output", "", "Also write every assessment as JSON to this file")
+	resolve := registerConfigFlags(fs)
+	fs.Parse(args)
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	targets, err := targetLocales(cfg, *localeFlag)
+	if err != nil {
+		return err
+	}
+
+	var all []syncer.Assessment
+	for _, locale := range targets {
+		assessments, err := syncer.Audit(cfg, locale)
+		if err != nil {
+			return err
+		}
+		all = append(all, assessments...)
+
+		flagged, penalty := 0, 0
+		for _, a := range assessments {
+			if len(a.Findings) > 0 {
+				flagged++
+			}
+			penalty += a.Penalty
+		}
+		fmt.Printf("%s: %d keys reviewed by %s, %d with errors, %d penalty points\n", locale, len(assessments), cfg.Judge(), flagged, penalty)
+		for _, a := range assessments {
+			if len(a.Findings) == 0 {
+				continue
+			}
+			fmt.Printf("  %-8s  %s\n", a.Severity(), a.Key)
+			for _, f := range a.Findings {
+				fmt.Printf("            %s\n", f)
+			}
+			fmt.Printf("            source: %q\n", a.Source)
+			fmt.Printf("            %s: %q\n", locale, a.Translation)
+		}
+	}
+
+	if *outputFlag != "" {
+		data, err := json.MarshalIndent(all, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding audit: %w", err)
+		}
+		if err := os.WriteFile(*outputFlag, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", *outputFlag, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/you/nogodey/internal/font"
+	"github.com/you/nogodey/internal/syncer"
+)
+
+// listFlag collects a flag that may be repeated or given a comma-separated
+// list.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, splitFlagList(v)...)
+	return nil
+}
+
+// runCheckCommand implements `nogodey check glyphs`, which reports
+// translations using characters the app's fonts cannot render, and
+// `nogodey check width`, which reports translations wider than their
+// key's pixel budget.
+func runCheckCommand(args []string) error {
+	if len(args) == 0 || args[0] != "glyphs" && args[0] != "width" {
+		return fmt.Errorf("usage: nogodey check glyphs|width [--font <path>] [options]")
+	}
+	check := args[0]
+	fs := flag.NewFlagSet("check "+check, flag.ExitOnError)
+	var fontPaths listFlag
+	fs.Var(&fontPaths, "font", "TrueType or OpenType font the app ships (repeatable, default: length.fonts)")
+	localeFlag := fs.String("locale", "", "Comma-separated locales to check (default: the configured locales)")
+	size := fs.Float64("size", 0, "Text size in pixels per em for keys without their own (check width)")
+	resolve := registerConfigFlags(fs)
+	fs.Parse(args[1:])
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	if len(fontPaths) == 0 {
+		fontPaths = cfg.Fonts
+	}
+	if len(fontPaths) == 0 {
+		return fmt.Errorf("at least one --font is required")
+	}
+	if *size > 0 {
+		cfg.FontSize = *size
+	}
+	fonts := make([]*font.Font, 0, len(fontPaths))
+	for _, path := range fontPaths {
+		f, err := font.Load(path)
+		if err != nil {
+			return err
+		}
+		fonts = append(fonts, f)
+	}
+	targets, err := targetLocales(cfg, *localeFlag)
+	if err != nil {
+		return err
+	}
+
+	if check == "width" {
+		issues, err := syncer.CheckWidths(cfg, targets, fonts)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) > 0 {
+			return fmt.Errorf("found %d translations likely to be truncated", len(issues))
+		}
+		return nil
+	}
+
+	issues, err := syncer.CheckGlyphs(cfg, targets, fonts)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d translations with missing glyphs", len(issues))
+	}
+	return nil
+}
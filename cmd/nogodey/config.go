@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/syncer"
+)
+
+// registerConfigFlags adds the flags shared by every command that needs a
+// resolved SyncConfig. The returned function must be called after Parse;
+// it loads the project file and layers flags > env > file > defaults.
+func registerConfigFlags(fs *flag.FlagSet) func() (syncer.SyncConfig, error) {
+	configFlag := fs.String("config", "", "Project file (default: first of nogodey.yaml, nogodey.yml, nogodey.json)")
+	localesFlag := fs.String("locales", syncer.DefaultLocale, "Comma-separated list of locales to sync (e.g., 'pcm,en,fr-CA'); aliases like 'pidgin' are accepted")
+	sourceLocaleFlag := fs.String("source-locale", "", "Locale the source strings are written in (default: en)")
+	pivotLocaleFlag := fs.String("pivot-locale", "", "Translate from this locale's existing translations instead of the source")
+	batchSizeFlag := fs.Int("batch-size", syncer.DefaultBatchSize, "Number of keys to process in each batch")
+	maxRetriesFlag := fs.Int("max-retries", syncer.DefaultMaxRetries, "Maximum number of retry attempts for failed API calls")
+	maxReferencesFlag := fs.Int("max-references", 0, "Quote existing translations of each key from up to this many other locales (0 disables)")
+	maxTokensFlag := fs.Int("max-tokens", 0, "Stop starting new batches once the run has used this many tokens (0 disables)")
+	maxCostFlag := fs.Float64("max-cost", 0, "Stop starting new batches once the run's estimated cost reaches this many USD (0 disables)")
+	providerFlag := fs.String("provider", "", "Translation provider: openai, pseudo to pseudo-localize without an API, or exec to run exec.command (default: openai)")
+	modelFlag := fs.String("model", "", "Model to translate with (default: gpt-3.5-turbo)")
+	fallbackModelsFlag := fs.String("fallback-models", "", "Comma-separated models, optionally provider/model, tried in order when the model keeps failing")
+	judgeModelFlag := fs.String("judge-model", "", "Model that reviews translations in audit (default: the translation model)")
+	onCollisionFlag := fs.String("on-collision", "", "How to handle keys with conflicting defaults: error, skip or first")
+	manifestFlag := fs.String("manifest", "", "Path to the extracted messages manifest (default: js/dist/messages.json)")
+	localesDirFlag := fs.String("locales-dir", "", "Directory holding locale files (default: js/locales)")
+	localePatternFlag := fs.String("locale-pattern", "", "Locale file name inside the locales directory (default: {locale}.json)")
+	styleGuideDirFlag := fs.String("style-guide-dir", "", "Directory of per-locale style guides named {locale}.yaml (default: js/locales/style)")
+	mergedDirFlag := fs.String("merged-dir", "", "Directory for runtime-ready locale files with fallbacks (default: js/dist/locales)")
+
+	return func() (syncer.SyncConfig, error) {
+		// Only flags the user passed take part in resolution, so their
+		// defaults do not shadow the environment or the project file.
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+		flags := syncer.Flags{
+			SourceLocale:  *sourceLocaleFlag,
+			PivotLocale:   *pivotLocaleFlag,
+			Provider:      *providerFlag,
+			Model:         *modelFlag,
+			JudgeModel:    *judgeModelFlag,
+			ManifestPath:  *manifestFlag,
+			LocalesDir:    *localesDirFlag,
+			LocalePattern: *localePatternFlag,
+			MergedDir:     *mergedDirFlag,
+			StyleGuideDir: *styleGuideDirFlag,
+			OnCollision:   *onCollisionFlag,
+		}
+		if set["locales"] {
+			flags.Locales = splitFlagList(*localesFlag)
+		}
+		if set["fallback-models"] {
+			flags.Fallbacks = splitFlagList(*fallbackModelsFlag)
+		}
+		if set["batch-size"] {
+			flags.BatchSize = batchSizeFlag
+		}
+		if set["max-retries"] {
+			flags.MaxRetries = maxRetriesFlag
+		}
+		if set["max-references"] {
+			flags.MaxReferences = maxReferencesFlag
+		}
+		if set["max-tokens"] {
+			flags.MaxTokens = maxTokensFlag
+		}
+		if set["max-cost"] {
+			flags.MaxCost = maxCostFlag
+		}
+
+		path := *configFlag
+		if path == "" {
+			path = config.Find(".")
+		}
+		var project *config.Project
+		if path != "" {
+			p, err := config.Load(path)
+			if err != nil {
+				printErrors(err)
+				return syncer.SyncConfig{}, fmt.Errorf("invalid project file %s", path)
+			}
+			project = p
+		}
+		return syncer.ResolveConfig(project, flags)
+	}
+}
+
+// splitFlagList parses a comma-separated flag value.
+func splitFlagList(s string) []string {
+	parts := strings.Split(s, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+// runConfigCommand implements `nogodey config print`.
+func runConfigCommand(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: nogodey config print [options]")
+	}
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	resolve := registerConfigFlags(fs)
+	fs.Parse(args[1:])
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, s := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.Value, s.Source)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "\nThe configuration is not valid:")
+		printErrors(err)
+		return syncer.ErrInvalidConfig
+	}
+	return nil
+}
+
+// printErrors writes each joined error on its own line so editors can jump
+// to file:line locations.
+func printErrors(err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			printErrors(e)
+		}
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
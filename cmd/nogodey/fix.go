@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/syncer"
+)
+
+// runFixCommand implements `nogodey fix`, which applies the post-processing
+// rules to translations already on disk.
+func runFixCommand(args []string) error {
+	fs := flag.NewFlagSet("fix", flag.ExitOnError)
+	localeFlag := fs.String("locale", "", "Comma-separated locales to fix (default: the configured locales)")
+	dryRun := fs.Bool("dry-run", false, "Only report the translations that would change")
+	resolve := registerConfigFlags(fs)
+	fs.Parse(args)
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	targets, err := targetLocales(cfg, *localeFlag)
+	if err != nil {
+		return err
+	}
+
+	log := logger.New()
+	for _, locale := range targets {
+		fixes, err := syncer.FixLocale(cfg, locale, *dryRun)
+		if err != nil {
+			return err
+		}
+		for _, f := range fixes {
+			log.Info("fixed translation", "locale", locale, "key", f.Key, "before", f.Before, "after", f.After, "dry_run", *dryRun)
+		}
+		log.Info("locale fixed", "locale", locale, "changed", len(fixes), "dry_run", *dryRun)
+	}
+	return nil
+}
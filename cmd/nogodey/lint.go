@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/syncer"
+)
+
+// runLintCommand implements `nogodey lint`, which runs the translation
+// checks over existing locale files and fails when any issue is found.
+func runLintCommand(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	localeFlag := fs.String("locale", "", "Comma-separated locales to lint (default: the configured locales)")
+	resolve := registerConfigFlags(fs)
+	fs.Parse(args)
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	targets, err := targetLocales(cfg, *localeFlag)
+	if err != nil {
+		return err
+	}
+
+	issues, err := syncer.Lint(cfg, targets)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d translation issues", len(issues))
+	}
+	return nil
+}
+
+// targetLocales returns the locales named by a --locale flag, or the
+// configured locales when it is empty.
+func targetLocales(cfg syncer.SyncConfig, flagValue string) ([]string, error) {
+	if flagValue == "" {
+		return cfg.Locales, nil
+	}
+	var targets []string
+	for _, l := range splitFlagList(flagValue) {
+		canonical, err := locales.Canonicalize(l)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, canonical)
+	}
+	return targets, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/syncer"
+)
+
+// runLocalesCommand implements `nogodey locales migrate`, which renames
+// locale files to their canonical BCP 47 names.
+func runLocalesCommand(args []string) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		return fmt.Errorf("usage: nogodey locales migrate [--dry-run] [options]")
+	}
+	fs := flag.NewFlagSet("locales migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Only report the files that would be renamed")
+	resolve := registerConfigFlags(fs)
+	fs.Parse(args[1:])
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+
+	log := logger.New()
+	migrations, err := syncer.MigrateLocales(cfg, *dryRun)
+	renamed := 0
+	for _, m := range migrations {
+		if m.Err != nil {
+			log.Warn("skipping locale file", "path", m.From, "error", m.Err.Error())
+			continue
+		}
+		renamed++
+		log.Info("migrated locale file", "from", m.From, "to", m.To, "merged", m.Merged, "dry_run", *dryRun)
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("locale migration completed", "files", renamed, "dry_run", *dryRun)
+	return nil
+}
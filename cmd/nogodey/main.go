@@ -1,12 +1,12 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/you/nogodey/cmd/nogodey/logger"
 	"github.com/you/nogodey/internal/syncer"
@@ -31,6 +31,41 @@ func main() {
 		runBuildCommand(log)
 	case "install":
 		runInstallCommand()
+	case "locales":
+		if err := runLocalesCommand(os.Args[2:]); err != nil {
+			log.Error("locales command failed", "error", err.Error())
+			os.Exit(1)
+		}
+	case "fix":
+		if err := runFixCommand(os.Args[2:]); err != nil {
+			log.Error("fix command failed", "error", err.Error())
+			os.Exit(1)
+		}
+	case "lint":
+		if err := runLintCommand(os.Args[2:]); err != nil {
+			log.Error("lint command failed", "error", err.Error())
+			os.Exit(1)
+		}
+	case "audit":
+		if err := runAuditCommand(os.Args[2:]); err != nil {
+			log.Error("audit command failed", "error", err.Error())
+			os.Exit(1)
+		}
+	case "verify":
+		if err := runVerifyCommand(os.Args[2:]); err != nil {
+			log.Error("verify command failed", "error", err.Error())
+			os.Exit(1)
+		}
+	case "check":
+		if err := runCheckCommand(os.Args[2:]); err != nil {
+			log.Error("check command failed", "error", err.Error())
+			os.Exit(1)
+		}
+	case "config":
+		if err := runConfigCommand(os.Args[2:]); err != nil {
+			log.Error("config command failed", "error", err.Error())
+			os.Exit(1)
+		}
 	case "help", "--help", "-h":
 		printHelp()
 	default:
@@ -41,24 +76,22 @@ func main() {
 }
 
 func runSyncCommand() error {
-	// Parse sync-specific flags
 	syncFlags := flag.NewFlagSet("sync", flag.ExitOnError)
-	localesFlag := syncFlags.String("locales", "pidgin", "Comma-separated list of locales to sync (e.g., 'pidgin,en,fr')")
-	batchSizeFlag := syncFlags.Int("batch-size", 200, "Number of keys to process in each batch")
-	maxRetriesFlag := syncFlags.Int("max-retries", 3, "Maximum number of retry attempts for failed API calls")
-
+	resolve := registerConfigFlags(syncFlags)
 	syncFlags.Parse(os.Args[2:])
 
-	// parse locales
-	locales := strings.Split(*localesFlag, ",")
-	for i, locale := range locales {
-		locales[i] = strings.TrimSpace(locale)
+	config, err := resolve()
+	if err != nil {
+		return err
 	}
-
-	// Get configuration from environment variables and flags
-	config := syncer.GetEnvConfig(locales, *batchSizeFlag, *maxRetriesFlag)
-
-	return syncer.SyncCommand(config)
+	// SyncCommand validates the configuration before doing any work; its
+	// errors are printed one per line so editors can jump to them.
+	err = syncer.SyncCommand(config)
+	if errors.Is(err, syncer.ErrInvalidConfig) {
+		printErrors(err)
+		return syncer.ErrInvalidConfig
+	}
+	return err
 }
 
 func runBuildCommand(log *logger.Logger) {
@@ -113,36 +146,92 @@ COMMANDS:
     build                    Build the JavaScript plugin (default)
     sync                     Sync translations using OpenAI
     install                  Install the plugin
+    fix                      Apply post-processing rules to existing translations (--locale, --dry-run)
+    lint                     Check existing translations against their source strings (--locale)
+    verify                   Back-translate translations and list the keys furthest from the source (--locale, --limit)
+    audit                    Have a judge model review translations with an MQM rubric (--locale, --output)
+    check glyphs             Report characters the app's fonts cannot render (--font, --locale)
+    check width              Report translations wider than their key's maxWidth (--font, --size, --locale)
+    config print             Show the resolved sync configuration and its sources
+    locales migrate          Rename locale files to canonical BCP 47 names (pidgin.json → pcm.json)
     help                     Show this help message
 
 SYNC OPTIONS:
-    --locales <locales>      Comma-separated list of locales (default: "pidgin")
+    --config <path>          Project file (default: nogodey.yaml, nogodey.yml or nogodey.json)
+    --locales <locales>      Comma-separated BCP 47 locales or aliases like "pidgin" (default: "pcm")
+    --source-locale <locale> Locale the source strings are written in (default: en)
+    --pivot-locale <locale>  Translate from this locale's existing translations
     --batch-size <size>      Keys per batch for translation (default: 200)
     --max-retries <count>    Max retry attempts for API calls (default: 3)
+    --max-references <n>     Quote a key's translations from up to n other locales (default: 0, off)
+    --max-tokens <n>         Stop starting new batches after n tokens (default: 0, no limit)
+    --max-cost <usd>         Stop starting new batches at this estimated cost (default: 0, no limit)
+    --judge-model <name>     Model that reviews translations in audit (default: --model)
+    --provider <name>        Translation provider: openai, pseudo or exec (default: openai)
+    --model <name>           Model to translate with (default: gpt-3.5-turbo)
+    --fallback-models <list> Models tried in order when the model keeps failing
+    --on-collision <policy>  Keys with conflicting defaults: error, skip or first (default: error)
+    --manifest <path>        Extracted messages manifest (default: js/dist/messages.json)
+    --locales-dir <dir>      Directory holding locale files (default: js/locales)
+    --locale-pattern <name>  Locale file name, {locale} is replaced (default: {locale}.json)
+    --merged-dir <dir>       Runtime-ready locale files with fallbacks (default: js/dist/locales)
+    --style-guide-dir <dir>  Per-locale style guides, {locale}.yaml (default: js/locales/style)
 
 EXAMPLES:
     nogodey build                     # Build plugin and extract strings
-    nogodey sync                      # Sync pidgin locale
-    nogodey sync --locales pidgin,en  # Sync multiple locales
+    nogodey sync                      # Sync Nigerian Pidgin (pcm)
+    nogodey sync --locales pcm,fr-CA  # Sync multiple locales
+    nogodey fix --locale fr --dry-run # Preview typography fixes for French
+    nogodey lint --locale de          # Report punctuation, number, URL and tag problems
+    nogodey verify --locale de        # Review the 20 weakest German translations
+    nogodey audit --locale de --judge-model gpt-4o --output audit.json
+    nogodey check glyphs --font assets/fonts/Inter.ttf
+    nogodey check width --font assets/fonts/Inter.ttf --size 14
+    nogodey locales migrate --dry-run # Preview renaming pidgin.json → pcm.json
     nogodey sync --batch-size 100     # Use smaller batches
+    nogodey sync --max-cost 5         # Stop starting batches after about $5
+    nogodey sync --locales en-XA --provider pseudo  # Pseudo-localize, no API key
+    nogodey sync --locales-dir src/i18n --locale-pattern '{locale}/common.json'
 
 WORKFLOW:
     1. Run 'nogodey build' to extract strings to js/dist/messages.json
     2. Run 'nogodey sync' to translate missing keys using OpenAI
     3. Translation files are saved to js/locales/{lang}.json
+       (see --manifest, --locales-dir and --locale-pattern to change these)
 
 ENVIRONMENT:
     OPENAI_API_KEY                    Required for sync command
     OPENAI_MODEL                      OpenAI model to use (default: gpt-3.5-turbo)
     SYNC_BATCH_SIZE                   Default batch size for translations
     SYNC_MAX_RETRIES                  Default max retry attempts
+    SYNC_MAX_REFERENCES               Default number of reference locales per key
+    SYNC_MAX_TOKENS                   Default token limit per run
+    SYNC_MAX_COST                     Default cost limit per run, in USD
+    SYNC_JUDGE_MODEL                  Model that reviews translations in audit
+    SYNC_FALLBACK_MODELS              Models tried when the model keeps failing
+    SYNC_REFERENCE_LOCALES            Locales quoted as references, in order
     SYNC_DEFAULT_LOCALES              Default locales to sync
+    SYNC_SOURCE_LOCALE                Locale of the source strings
+    SYNC_PIVOT_LOCALE                 Default pivot locale
+    SYNC_PROVIDER                     Default translation provider
+    SYNC_ON_COLLISION                 Default collision policy (error, skip, first)
+    SYNC_MANIFEST                     Default manifest path
+    SYNC_LOCALES_DIR                  Default locales directory
+    SYNC_LOCALE_PATTERN               Default locale file pattern
+    SYNC_MERGED_DIR                   Default directory for merged locale files
+    SYNC_STYLE_GUIDE_DIR              Default directory for style guides
+    NOGODEY_LLM_MODE                  record or replay model calls through a cassette
+    NOGODEY_LLM_CASSETTE              Cassette file (default: testdata/llm-cassette.json)
 
 CONFIGURATION:
-    Create a .env file in the project root with your settings:
+    Project settings live in nogodey.yaml (or nogodey.json) in the project
+    root; see nogodey.example.yaml. Precedence is flags > environment >
+    project file > defaults, and 'nogodey config print' shows which one won.
+
+    Secrets such as the API key belong in a .env file instead:
     
     OPENAI_API_KEY=your-api-key-here
     OPENAI_MODEL=gpt-4
     SYNC_BATCH_SIZE=150
-    SYNC_DEFAULT_LOCALES=pidgin,en`)
+    SYNC_DEFAULT_LOCALES=pcm,en`)
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/you/nogodey/internal/syncer"
+)
+
+// runVerifyCommand implements `nogodey verify`, which back-translates
+// locale files and lists the keys that drifted furthest from their source
+// text for human review.
+func runVerifyCommand(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	localeFlag := fs.String("locale", "", "Comma-separated locales to verify (default: the configured locales)")
+	limit := fs.Int("limit", 20, "Number of lowest-scoring keys to list per locale (0 lists all)")
+	resolve := registerConfigFlags(fs)
+	fs.Parse(args)
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	targets, err := targetLocales(cfg, *localeFlag)
+	if err != nil {
+		return err
+	}
+
+	for _, locale := range targets {
+		verdicts, err := syncer.Verify(cfg, locale)
+		if err != nil {
+			return err
+		}
+		var sum float64
+		scored := 0
+		for _, v := range verdicts {
+			if !v.Unscored {
+				sum += v.Score
+				scored++
+			}
+		}
+		mean := 0.0
+		if scored > 0 {
+			mean = sum / float64(scored)
+		}
+		fmt.Printf("%s: %d keys, mean score %.2f", locale, len(verdicts), mean)
+		if unscored := len(verdicts) - scored; unscored > 0 {
+			fmt.Printf(", %d unscored", unscored)
+		}
+		fmt.Println()
+		if *limit > 0 && len(verdicts) > *limit {
+			verdicts = verdicts[:*limit]
+		}
+		for _, v := range verdicts {
+			if v.Unscored {
+				fmt.Printf("  ----  %s\n", v.Key)
+			} else {
+				fmt.Printf("  %.2f  %s\n", v.Score, v.Key)
+			}
+			fmt.Printf("        source: %q\n", v.Source)
+			fmt.Printf("        %s: %q\n", locale, v.Translation)
+			if v.Unscored {
+				fmt.Printf("        back: none passed the checks\n")
+			} else {
+				fmt.Printf("        back: %q\n", v.BackTranslation)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package checks compares a translation with its source string for
+// mechanical mistakes models make: dropped punctuation, stray whitespace,
+// changed numbers, URLs or emails, lost emoji and broken markup tags.
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Issue is one problem found in a translation.
+type Issue struct {
+	Key   string
+	Check string
+	Msg   string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: [%s] %s", i.Key, i.Check, i.Msg)
+}
+
+// Check is a named comparison of a source string and its translation. Run
+// returns one message per problem.
+type Check struct {
+	Name string
+	Doc  string
+	Run  func(source, translation string) []string
+}
+
+// Checks lists every check in the order they run.
+var Checks = []Check{
+	{"punctuation", "Terminal punctuation matches the source", checkPunctuation},
+	{"whitespace", "Leading and trailing whitespace matches the source", checkWhitespace},
+	{"numbers", "Numbers in the source are kept", checkNumbers},
+	{"urls", "URLs are copied unchanged", tokensCheck("URL", urlRe)},
+	{"emails", "Email addresses are copied unchanged", tokensCheck("email", emailRe)},
+	{"emoji", "Emoji are kept", checkEmoji},
+	{"tags", "JSX-like tags are kept and properly nested", checkTags},
+}
+
+// Names returns the names of all checks.
+func Names() []string {
+	names := make([]string, len(Checks))
+	for i, c := range Checks {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Suite is the set of checks enabled for a project.
+type Suite struct {
+	checks []Check
+}
+
+// New returns a suite running every check except the disabled ones.
+// external names checks run outside the suite, such as wrong-language
+// detection, that may be disabled too.
+func New(disabled []string, external ...string) (Suite, error) {
+	names := append(Names(), external...)
+	for _, d := range disabled {
+		if !slices.Contains(names, d) {
+			return Suite{}, fmt.Errorf("unknown check %q, want one of %s", d, strings.Join(names, ", "))
+		}
+	}
+	var s Suite
+	for _, c := range Checks {
+		if !slices.Contains(disabled, c.Name) {
+			s.checks = append(s.checks, c)
+		}
+	}
+	return s, nil
+}
+
+// Run returns the issues found in the translation of key.
+func (s Suite) Run(key, source, translation string) []Issue {
+	var issues []Issue
+	for _, c := range s.checks {
+		for _, msg := range c.Run(source, translation) {
+			issues = append(issues, Issue{Key: key, Check: c.Name, Msg: msg})
+		}
+	}
+	return issues
+}
+
+// terminals maps terminal punctuation to the equivalents accepted in a
+// translation, e.g. a full-width question mark in Japanese.
+var terminals = map[rune][]rune{
+	'.': {'.', '。', '।', '۔'},
+	'?': {'?', '？', '؟'},
+	'!': {'!', '！'},
+	':': {':', '：'},
+	';': {';', '；', '؛'},
+	'…': {'…'},
+}
+
+func terminal(s string) rune {
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	if strings.HasSuffix(s, "...") {
+		return '…'
+	}
+	r, _ := utf8.DecodeLastRuneInString(s)
+	for t, equivalents := range terminals {
+		if slices.Contains(equivalents, r) {
+			return t
+		}
+	}
+	return 0
+}
+
+func checkPunctuation(source, translation string) []string {
+	want, got := terminal(source), terminal(translation)
+	switch {
+	case want == got:
+		return nil
+	case want == 0:
+		return []string{fmt.Sprintf("ends with %q but the source has no terminal punctuation", got)}
+	case got == 0:
+		return []string{fmt.Sprintf("missing terminal %q", want)}
+	case want == '!' && got == '.', want == '.' && got == '!':
+		// Languages differ in how emphatic UI copy is.
+		return nil
+	default:
+		return []string{fmt.Sprintf("ends with %q, source ends with %q", got, want)}
+	}
+}
+
+func checkWhitespace(source, translation string) []string {
+	var msgs []string
+	lead := func(s string) bool { r, _ := utf8.DecodeRuneInString(s); return unicode.IsSpace(r) }
+	trail := func(s string) bool { r, _ := utf8.DecodeLastRuneInString(s); return unicode.IsSpace(r) }
+	if lead(source) != lead(translation) {
+		msgs = append(msgs, mismatch("leading whitespace", lead(source)))
+	}
+	if trail(source) != trail(translation) {
+		msgs = append(msgs, mismatch("trailing whitespace", trail(source)))
+	}
+	return msgs
+}
+
+func mismatch(what string, inSource bool) string {
+	if inSource {
+		return what + " was removed"
+	}
+	return what + " was added"
+}
+
+var numberRe = regexp.MustCompile(`\d+(?:[.,\x{00A0}\x{202F} ]\d{3})*(?:[.,]\d+)?`)
+
+// checkNumbers compares the digits of every number, ignoring grouping and
+// decimal separators, which legitimately change between locales.
+func checkNumbers(source, translation string) []string {
+	digits := func(s string) []string {
+		var out []string
+		for _, n := range numberRe.FindAllString(s, -1) {
+			out = append(out, strings.Map(func(r rune) rune {
+				if r >= '0' && r <= '9' {
+					return r
+				}
+				return -1
+			}, n))
+		}
+		slices.Sort(out)
+		return out
+	}
+	missing, extra := diff(digits(source), digits(translation))
+	return describe("number", missing, extra)
+}
+
+var (
+	urlRe   = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+[^\s<>"'.,;:!?)]`)
+	emailRe = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`)
+)
+
+func tokensCheck(what string, re *regexp.Regexp) func(string, string) []string {
+	return func(source, translation string) []string {
+		missing, extra := diff(sorted(re.FindAllString(source, -1)), sorted(re.FindAllString(translation, -1)))
+		return describe(what, missing, extra)
+	}
+}
+
+func isEmoji(r rune) bool {
+	return r >= 0x1F000 && r <= 0x1FAFF || r >= 0x2600 && r <= 0x27BF
+}
+
+func checkEmoji(source, translation string) []string {
+	emoji := func(s string) []string {
+		var out []string
+		for _, r := range s {
+			if isEmoji(r) {
+				out = append(out, string(r))
+			}
+		}
+		slices.Sort(out)
+		return out
+	}
+	missing, extra := diff(emoji(source), emoji(translation))
+	return describe("emoji", missing, extra)
+}
+
+var tagRe = regexp.MustCompile(`<(/?)([A-Za-z][\w.-]*)\b[^<>]*?(/?)>`)
+
+// checkTags compares the tags of both strings, attributes included, and
+// checks that the translation nests them properly. Tags may move, since
+// word order changes between languages.
+func checkTags(source, translation string) []string {
+	missing, extra := diff(sorted(tagRe.FindAllString(source, -1)), sorted(tagRe.FindAllString(translation, -1)))
+	msgs := describe("tag", missing, extra)
+	if len(msgs) == 0 {
+		if msg := nesting(translation); msg != "" && nesting(source) == "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// nesting returns a description of the first nesting error in s, or "".
+func nesting(s string) string {
+	var open []string
+	for _, m := range tagRe.FindAllStringSubmatch(s, -1) {
+		closing, name, selfClosing := m[1] == "/", m[2], m[3] == "/"
+		switch {
+		case selfClosing:
+		case !closing:
+			open = append(open, name)
+		case len(open) == 0 || open[len(open)-1] != name:
+			return fmt.Sprintf("closing tag </%s> does not match an open tag", name)
+		default:
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Sprintf("tag <%s> is not closed", open[len(open)-1])
+	}
+	return ""
+}
+
+func sorted(s []string) []string {
+	slices.Sort(s)
+	return s
+}
+
+// diff compares two sorted multisets.
+func diff(want, got []string) (missing, extra []string) {
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case j == len(got) || i < len(want) && want[i] < got[j]:
+			missing = append(missing, want[i])
+			i++
+		case i == len(want) || got[j] < want[i]:
+			extra = append(extra, got[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return missing, extra
+}
+
+func describe(what string, missing, extra []string) []string {
+	var msgs []string
+	for _, m := range missing {
+		msgs = append(msgs, fmt.Sprintf("%s %q is missing", what, m))
+	}
+	for _, e := range extra {
+		msgs = append(msgs, fmt.Sprintf("%s %q is not in the source", what, e))
+	}
+	return msgs
+}
@@ -0,0 +1,75 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, source, translation string) []string {
+	t.Helper()
+	s, err := New(nil)
+	require.NoError(t, err)
+	var out []string
+	for _, i := range s.Run("k", source, translation) {
+		out = append(out, i.Check+": "+i.Msg)
+	}
+	return out
+}
+
+func TestPunctuation(t *testing.T) {
+	assert.Empty(t, run(t, "Delete file?", "Datei löschen?"))
+	assert.Empty(t, run(t, "Delete file?", "ファイルを削除しますか？"))
+	assert.Empty(t, run(t, "Loading...", "Chargement…"))
+	assert.Equal(t, []string{`punctuation: missing terminal '?'`}, run(t, "Delete file?", "Datei löschen"))
+	assert.Equal(t, []string{`punctuation: ends with '.' but the source has no terminal punctuation`}, run(t, "Save", "Speichern."))
+	assert.Equal(t, []string{`punctuation: ends with ':', source ends with '?'`}, run(t, "Name?", "Nom :"))
+}
+
+func TestWhitespace(t *testing.T) {
+	assert.Empty(t, run(t, " of ", " von "))
+	assert.Equal(t, []string{"whitespace: leading whitespace was added"}, run(t, "Save", " Speichern"))
+	assert.Equal(t, []string{"whitespace: trailing whitespace was removed"}, run(t, "Total: ", "Gesamt:"))
+}
+
+func TestNumbers(t *testing.T) {
+	assert.Empty(t, run(t, "Up to 1,000 files, 2.5 GB", "Bis zu 1.000 Dateien, 2,5 GB"))
+	assert.Equal(t, []string{`numbers: number "10" is missing`, `numbers: number "100" is not in the source`}, run(t, "Save 10%", "Économisez 100%"))
+}
+
+func TestURLsAndEmails(t *testing.T) {
+	assert.Empty(t, run(t, "See https://example.com/terms.", "Voir https://example.com/terms."))
+	assert.Equal(t, []string{`urls: URL "https://example.com/terms" is missing`, `urls: URL "https://example.fr/terms" is not in the source`},
+		run(t, "See https://example.com/terms", "Voir https://example.fr/terms"))
+	assert.Equal(t, []string{`emails: email "help@example.com" is missing`}, run(t, "Write to help@example.com", "Écrivez-nous"))
+}
+
+func TestEmoji(t *testing.T) {
+	assert.Empty(t, run(t, "Done 🎉", "Fertig 🎉"))
+	assert.Equal(t, []string{`emoji: emoji "🎉" is missing`}, run(t, "Done 🎉", "Fertig"))
+}
+
+func TestTags(t *testing.T) {
+	assert.Empty(t, run(t, "Read the <Link>terms</Link>", "Lies die <Link>AGB</Link>"))
+	assert.Empty(t, run(t, "<b>{name}</b> joined <i>today</i>", "<i>Heute</i> ist <b>{name}</b> beigetreten"))
+	assert.Equal(t, []string{`tags: tag "</Link>" is missing`}, run(t, "Read the <Link>terms</Link>", "Lies die <Link>AGB"))
+	assert.Equal(t, []string{`tags: tag "<Link>" is missing`, `tags: tag "<Lien>" is not in the source`}, run(t, "<Link>terms</Link>", "<Lien>conditions</Link>"))
+	assert.Equal(t, []string{"tags: closing tag </b> does not match an open tag"}, run(t, "<b><i>x</i></b>", "<b><i>x</b></i>"))
+}
+
+func TestNew_Disabled(t *testing.T) {
+	s, err := New([]string{"punctuation"})
+	require.NoError(t, err)
+	assert.Empty(t, s.Run("k", "Delete?", "Löschen"))
+
+	_, err = New([]string{"grammar"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown check "grammar"`)
+
+	s, err = New([]string{"language"}, "language")
+	require.NoError(t, err, "external checks may be disabled")
+	assert.NotEmpty(t, s.Run("k", "Delete?", "Löschen"))
+	_, err = New([]string{"grammar"}, "language")
+	assert.ErrorContains(t, err, "tags, language")
+}
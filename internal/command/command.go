@@ -0,0 +1,149 @@
+// Package command runs an external translator, such as a vendor's machine
+// translation CLI, as a translation provider.
+//
+// For every batch the command is started once. It reads a Request as JSON
+// on stdin and writes a Response as JSON on stdout; stderr is shown in
+// errors. The exit status tells nogodey what to do next:
+//
+//	0   the translations on stdout are used; keys left out are asked for
+//	    again, like keys a model skipped
+//	75  a temporary failure (EX_TEMPFAIL), e.g. a rate limit: the batch is
+//	    retried after a backoff
+//	any other status, or a command that cannot be started, is fatal: the
+//	batch is not retried with this provider, only fallback models are
+//	tried
+//
+// A command that does not finish within the call timeout is killed and
+// counts as a temporary failure. Texts contain tokens such as ⟦0⟧ that
+// stand for markup and placeholders; every token must appear exactly once
+// in the translation.
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/you/nogodey/internal/llm"
+)
+
+// Version is the protocol version sent with every request.
+const Version = 1
+
+// ExitRetry is the exit status of a temporary failure.
+const ExitRetry = 75
+
+// Request is what the command reads on stdin.
+type Request struct {
+	Version int `json:"version"`
+	// SourceLocale and Locale are BCP 47 tags of the languages to
+	// translate from and to.
+	SourceLocale string `json:"sourceLocale"`
+	Locale       string `json:"locale"`
+	// Model is the configured model name, for commands that offer several
+	// engines.
+	Model    string    `json:"model,omitempty"`
+	Messages []Message `json:"messages"`
+}
+
+// Message is one string to translate with its context: the key, the
+// text, the original when the text is a translation to adapt, references
+// in other locales and a length budget. It is the item of the prompt's
+// data block, so the command sees exactly what a model would.
+type Message = llm.DataItem
+
+// Response is what the command writes on stdout.
+type Response struct {
+	Translations map[string]string `json:"translations"`
+	// Usage is optional and counted like a model's token usage.
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// Usage counts the units the engine bills, as tokens.
+type Usage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+}
+
+// Client is a translation provider that runs Command for every request.
+type Client struct {
+	// Command is the program and its arguments.
+	Command []string
+}
+
+// CreateChatCompletion sends the <data> block of the prompt to the command
+// and answers with its translations as a JSON object.
+func (c Client) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if len(c.Command) == 0 {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("exec: no command configured: %w", llm.ErrFatal)
+	}
+	if len(req.Messages) == 0 {
+		return openai.ChatCompletionResponse{}, errors.New("exec: empty request")
+	}
+	items, err := llm.ReadData(req.Messages[len(req.Messages)-1].Content)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("exec: %w: %w", err, llm.ErrFatal)
+	}
+	source, locale := llm.Locales(ctx)
+	in := Request{Version: Version, SourceLocale: source, Locale: locale, Model: req.Model, Messages: items}
+	stdin, err := json.Marshal(in)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("exec: encoding request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return openai.ChatCompletionResponse{}, c.failure(ctx, err, stderr.String())
+	}
+
+	var out Response
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("exec: %s wrote invalid JSON: %w", c.Command[0], err)
+	}
+	content, err := json.Marshal(out.Translations)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("exec: encoding translations: %w", err)
+	}
+	resp := openai.ChatCompletionResponse{
+		Model:   req.Model,
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: string(content)}, FinishReason: openai.FinishReasonStop}},
+	}
+	if out.Usage != nil {
+		resp.Usage = openai.Usage{PromptTokens: out.Usage.PromptTokens, CompletionTokens: out.Usage.CompletionTokens, TotalTokens: out.Usage.PromptTokens + out.Usage.CompletionTokens}
+	}
+	return resp, nil
+}
+
+// failure classifies a failed run by the protocol in the package comment.
+func (c Client) failure(ctx context.Context, err error, stderr string) error {
+	msg := lastLine(stderr)
+	var exit *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("exec: %s did not finish in time: %w", c.Command[0], ctx.Err())
+	case errors.As(err, &exit) && exit.ExitCode() == ExitRetry:
+		return fmt.Errorf("exec: %s failed temporarily: %s", c.Command[0], msg)
+	case errors.As(err, &exit):
+		return fmt.Errorf("exec: %s exited with status %d: %s: %w", c.Command[0], exit.ExitCode(), msg, llm.ErrFatal)
+	default:
+		return fmt.Errorf("exec: %w: %w", err, llm.ErrFatal)
+	}
+}
+
+// lastLine returns the last non-empty line of stderr, where commands put
+// the reason they failed.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		return line
+	}
+	return "no error output"
+}
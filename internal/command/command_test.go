@@ -0,0 +1,88 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/llm"
+)
+
+// TestHelperProcess is the translator command the tests run. It behaves
+// as NOGODEY_TEST_TRANSLATOR says and is skipped otherwise.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv("NOGODEY_TEST_TRANSLATOR")
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request:", err)
+		os.Exit(2)
+	}
+	switch mode {
+	case "ok":
+		out := Response{Translations: map[string]string{}, Usage: &Usage{PromptTokens: 7, CompletionTokens: 3}}
+		for _, m := range req.Messages {
+			out.Translations[m.Key] = fmt.Sprintf("%s:%s:%s", req.SourceLocale, req.Locale, m.Text)
+		}
+		_ = json.NewEncoder(os.Stdout).Encode(out)
+	case "busy":
+		fmt.Fprintln(os.Stderr, "rate limited")
+		os.Exit(ExitRetry)
+	case "reject":
+		fmt.Fprintln(os.Stderr, "starting engine\nunsupported language pair")
+		os.Exit(3)
+	case "garbage":
+		fmt.Println("not json")
+	}
+	os.Exit(0)
+}
+
+const prompt = "Translate the <data> block.\n<data>\n{\n  \"save\": {\"text\": \"Save ⟦0⟧\"},\n  \"open\": {\"text\": \"Öffnen\", \"source\": \"Open\"}\n}\n</data>\n"
+
+// run sends prompt to the helper process in mode.
+func run(t *testing.T, mode string) (openai.ChatCompletionResponse, error) {
+	t.Helper()
+	t.Setenv("NOGODEY_TEST_TRANSLATOR", mode)
+	c := Client{Command: []string{os.Args[0], "-test.run=^TestHelperProcess$"}}
+	ctx := llm.WithLocales(context.Background(), "en", "de")
+	return c.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    "engine",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: prompt}},
+	})
+}
+
+func TestClient_Translates(t *testing.T) {
+	resp, err := run(t, "ok")
+	require.NoError(t, err)
+	var got map[string]string
+	require.NoError(t, json.Unmarshal([]byte(resp.Choices[0].Message.Content), &got))
+	assert.Equal(t, map[string]string{"save": "en:de:Save ⟦0⟧", "open": "en:de:Öffnen"}, got)
+	assert.Equal(t, 10, resp.Usage.TotalTokens)
+}
+
+func TestClient_ExitStatus(t *testing.T) {
+	_, err := run(t, "busy")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed temporarily: rate limited")
+	assert.NotErrorIs(t, err, llm.ErrFatal, "status 75 is retried")
+
+	_, err = run(t, "reject")
+	require.ErrorIs(t, err, llm.ErrFatal)
+	assert.Contains(t, err.Error(), "exited with status 3: unsupported language pair")
+
+	_, err = run(t, "garbage")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, llm.ErrFatal, "a bad answer is asked for again")
+
+	_, err = Client{Command: []string{"nogodey-no-such-translator"}}.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: prompt}},
+	})
+	require.ErrorIs(t, err, llm.ErrFatal)
+}
@@ -0,0 +1,516 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the project file names Find looks for, in order. JSON is a
+// subset of YAML, so both are read by the same decoder.
+var FileNames = []string{"nogodey.yaml", "nogodey.yml", "nogodey.json"}
+
+// Providers lists the translation providers the sync command understands.
+var Providers = []string{"openai", "pseudo", "exec"}
+
+// Project is the schema of nogodey.yaml / nogodey.json. Unset fields are
+// left to environment variables and built-in defaults.
+type Project struct {
+	Locales       []string                 `yaml:"locales"`
+	SourceLocale  string                   `yaml:"sourceLocale"`
+	PivotLocale   string                   `yaml:"pivotLocale"`
+	Paths         Paths                    `yaml:"paths"`
+	Provider      string                   `yaml:"provider"`
+	Model         string                   `yaml:"model"`
+	Batching      Batching                 `yaml:"batching"`
+	OnCollision   string                   `yaml:"onCollision"`
+	Glossary      []GlossaryEntry          `yaml:"glossary"`
+	LocaleOptions map[string]LocaleOptions `yaml:"localeOptions"`
+	References    References               `yaml:"references"`
+	Checks        Checks                   `yaml:"checks"`
+	Length        Length                   `yaml:"length"`
+	Judge         Judge                    `yaml:"judge"`
+	Candidates    Candidates               `yaml:"candidates"`
+	Pseudo        Pseudo                   `yaml:"pseudo"`
+	Exec          Exec                     `yaml:"exec"`
+	// Fallbacks are tried in order when the model keeps failing, as
+	// "provider/model" or a model of the project's provider.
+	Fallbacks []string `yaml:"fallbacks"`
+	// Pricing sets the price of models, adding to or overriding the
+	// built-in prices.
+	Pricing map[string]Price `yaml:"pricing"`
+
+	// Path is the file the project was loaded from.
+	Path string `yaml:"-"`
+}
+
+// Paths locates the manifest and the locale files.
+type Paths struct {
+	Manifest      string `yaml:"manifest"`
+	LocalesDir    string `yaml:"localesDir"`
+	LocalePattern string `yaml:"localePattern"`
+	// MergedDir receives runtime-ready locale files with inherited and
+	// source fallbacks filled in.
+	MergedDir string `yaml:"mergedDir"`
+	// StyleGuideDir holds per-locale style guides named {locale}.yaml.
+	StyleGuideDir string `yaml:"styleGuideDir"`
+	// AlternatesDir receives the candidate translations that were not
+	// chosen, named like the locale files.
+	AlternatesDir string `yaml:"alternatesDir"`
+	// ModelsDir records which model produced each translation.
+	ModelsDir string `yaml:"modelsDir"`
+}
+
+// Batching controls how missing keys are grouped into API calls. Pointers
+// distinguish "not set" from an explicit zero so validation can catch it.
+type Batching struct {
+	Size       *int `yaml:"size"`
+	MaxRetries *int `yaml:"maxRetries"`
+	// MaxTokens and MaxCost, in USD, stop a sync from starting new
+	// batches once the run has used that much. Zero means no limit.
+	MaxTokens *int     `yaml:"maxTokens"`
+	MaxCost   *float64 `yaml:"maxCost"`
+}
+
+// Price is what a model costs in USD per million tokens.
+type Price struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// References controls which existing translations of a key in other
+// locales are shown to the model to disambiguate short source strings.
+type References struct {
+	// Max is the number of other locales quoted per key; 0 disables them.
+	Max *int `yaml:"max"`
+	// Locales limits and orders the candidates. By default every locale
+	// file on disk is a candidate, in alphabetical order.
+	Locales []string `yaml:"locales"`
+}
+
+// Checks configures the mechanical checks run on every translation.
+type Checks struct {
+	// Disable lists checks to skip, see package checks; "language" turns
+	// off wrong-language detection.
+	Disable []string `yaml:"disable"`
+	// AllowIdentical lists keys or source strings whose translation may
+	// be the source text itself, such as brand names.
+	AllowIdentical []string `yaml:"allowIdentical"`
+}
+
+// Length sets translation length budgets so labels do not overflow.
+type Length struct {
+	// Ratios caps translations at a multiple of the source length per
+	// message kind: "text", or an attribute such as "title". A ratio of 0
+	// turns off the budget of a kind, including a built-in one.
+	Ratios map[string]float64 `yaml:"ratios"`
+	// Keys sets the budget of single keys, overriding the manifest.
+	Keys map[string]Budget `yaml:"keys"`
+	// Fonts are the font files the app renders text with, used to
+	// measure translations against maxWidth budgets.
+	Fonts []string `yaml:"fonts"`
+	// FontSize is the default text size in pixels per em.
+	FontSize float64 `yaml:"fontSize"`
+}
+
+// Judge configures the model that reviews translations in `nogodey audit`.
+type Judge struct {
+	// Model defaults to the translation model. A different, stronger
+	// model avoids a model grading its own work.
+	Model string `yaml:"model"`
+}
+
+// Pseudo configures the pseudo provider, which pseudo-localizes instead
+// of translating.
+type Pseudo struct {
+	// Ratio pads pseudo-localized strings to this multiple of the source
+	// length, e.g. 1.4 for 40% longer.
+	Ratio float64 `yaml:"ratio"`
+}
+
+// Exec configures the exec provider, an external translator command, see
+// package command.
+type Exec struct {
+	// Command is the program and its arguments, e.g.
+	// ["vendor-mt", "translate", "--json"].
+	Command []string `yaml:"command"`
+}
+
+// Candidates has sync generate several translations of high-visibility
+// strings and keep the best one.
+type Candidates struct {
+	// Keys are path.Match patterns, e.g. "onboarding-*".
+	Keys []string `yaml:"keys"`
+	// Count is the number of candidates per key.
+	Count *int `yaml:"count"`
+	// Temperatures and Models are cycled through, one per candidate.
+	// Models default to the locale's model.
+	Temperatures []float32 `yaml:"temperatures"`
+	Models       []string  `yaml:"models"`
+	// Judge has the judge model review the candidates as well.
+	Judge bool `yaml:"judge"`
+}
+
+// Budget limits the length of one translation. Zero means no limit.
+type Budget struct {
+	MaxLength    int     `yaml:"maxLength"`
+	MaxExpansion float64 `yaml:"maxExpansion"`
+	// MaxWidth is the rendered width in pixels, at FontSize when set or
+	// the project default.
+	MaxWidth float64 `yaml:"maxWidth"`
+	FontSize float64 `yaml:"fontSize"`
+}
+
+// GlossaryEntry pins how a term is handled in translations.
+type GlossaryEntry struct {
+	Term string `yaml:"term"`
+	// Note is free-form guidance for the model, e.g. "brand name".
+	Note string `yaml:"note"`
+	// Translations maps a locale to the required rendering of Term. A
+	// missing locale means the term is kept as is.
+	Translations map[string]string `yaml:"translations"`
+}
+
+// LocaleOptions overrides project settings for a single locale.
+type LocaleOptions struct {
+	Model string `yaml:"model"`
+	// Instructions are appended to the translation prompt for this locale.
+	Instructions string `yaml:"instructions"`
+	// Parent is the locale this one inherits from, e.g. fr for fr-CA.
+	// Missing keys are seeded from the parent and only adapted.
+	Parent string `yaml:"parent"`
+	// Pivot overrides the project pivot locale for this locale.
+	Pivot string `yaml:"pivot"`
+	// StyleGuide is the path of this locale's style guide, overriding
+	// {styleGuideDir}/{locale}.yaml.
+	StyleGuide string `yaml:"styleGuide"`
+	// PostProcess lists the normalisation rules applied to translations,
+	// see package postprocess. Unset selects the language defaults and an
+	// empty list turns post-processing off.
+	PostProcess []string `yaml:"postProcess"`
+}
+
+// FieldError is a problem in the project file, located by line.
+type FieldError struct {
+	File  string
+	Line  int
+	Field string
+	Msg   string
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", e.File, e.Line, e.Field, e.Msg)
+}
+
+// Find returns the first project file present in dir, or "" when there is
+// none.
+func Find(dir string) string {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Load reads and validates a project file. All problems found are returned
+// together as FieldErrors joined with errors.Join.
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return Parse(path, data)
+}
+
+// Parse validates and decodes project file contents. name is only used in
+// error messages.
+func Parse(name string, data []byte) (*Project, error) {
+	p := &Project{Path: name}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return p, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlErrors(name, err)
+	}
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, FieldError{File: name, Line: root.Line, Msg: "project file must be a mapping"}
+	}
+
+	var errs []error
+	checkFields(name, root, reflect.TypeOf(Project{}), "", &errs)
+	if err := root.Decode(p); err != nil {
+		errs = append(errs, yamlErrors(name, err))
+	}
+	if len(errs) == 0 {
+		errs = p.validate(root)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return p, nil
+}
+
+// validate checks values that decode fine but make no sense. Values can
+// come in through aliases and merge keys, so the node of a field may be
+// elsewhere in the file or missing; lineAt finds the nearest line.
+func (p *Project) validate(root *yaml.Node) []error {
+	var errs []error
+	fail := func(line int, field, format string, args ...any) {
+		errs = append(errs, FieldError{File: p.Path, Line: line, Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	for i, l := range p.Locales {
+		if strings.TrimSpace(l) == "" {
+			fail(lineAt(root, "locales", i), fmt.Sprintf("locales[%d]", i), "locale must not be empty")
+		}
+	}
+	if p.Provider != "" && !slices.Contains(Providers, p.Provider) {
+		fail(lineAt(root, "provider"), "provider", "unknown provider %q (want one of %s)", p.Provider, strings.Join(Providers, ", "))
+	}
+	switch p.OnCollision {
+	case "", "error", "skip", "first":
+	default:
+		fail(lineAt(root, "onCollision"), "onCollision", "unknown policy %q (want error, skip or first)", p.OnCollision)
+	}
+	for i, g := range p.Glossary {
+		if strings.TrimSpace(g.Term) == "" {
+			fail(lineAt(root, "glossary", i), fmt.Sprintf("glossary[%d].term", i), "term must not be empty")
+		}
+	}
+	for kind, r := range p.Length.Ratios {
+		if r < 0 {
+			fail(lineAt(root, "length", "ratios", kind), "length.ratios."+kind, "ratio must not be negative, got %v", r)
+		}
+	}
+	for key, b := range p.Length.Keys {
+		if b.MaxLength < 0 || b.MaxExpansion < 0 || b.MaxWidth < 0 || b.FontSize < 0 {
+			fail(lineAt(root, "length", "keys", key), "length.keys."+key, "budget must not be negative")
+		}
+	}
+	if p.Length.FontSize < 0 {
+		fail(lineAt(root, "length", "fontSize"), "length.fontSize", "must not be negative, got %v", p.Length.FontSize)
+	}
+	for i, k := range p.Candidates.Keys {
+		if _, err := path.Match(k, ""); err != nil {
+			fail(lineAt(root, "candidates", "keys", i), fmt.Sprintf("candidates.keys[%d]", i), "invalid pattern %q", k)
+		}
+	}
+	if c := p.Candidates.Count; c != nil && *c < 1 {
+		fail(lineAt(root, "candidates", "count"), "candidates.count", "must be at least 1, got %d", *c)
+	}
+	for i, t := range p.Candidates.Temperatures {
+		if t < 0 || t > 2 {
+			fail(lineAt(root, "candidates", "temperatures", i), fmt.Sprintf("candidates.temperatures[%d]", i), "must be between 0 and 2, got %v", t)
+		}
+	}
+	if p.Provider == "exec" && len(p.Exec.Command) == 0 {
+		fail(lineAt(root, "provider"), "provider", "the exec provider needs exec.command")
+	}
+	if len(p.Exec.Command) > 0 && strings.TrimSpace(p.Exec.Command[0]) == "" {
+		fail(lineAt(root, "exec", "command"), "exec.command", "program must not be empty")
+	}
+	if p.Pseudo.Ratio != 0 && p.Pseudo.Ratio < 1 {
+		fail(lineAt(root, "pseudo", "ratio"), "pseudo.ratio", "must be at least 1, got %v", p.Pseudo.Ratio)
+	}
+	if m := p.Batching.MaxTokens; m != nil && *m < 0 {
+		fail(lineAt(root, "batching", "maxTokens"), "batching.maxTokens", "must not be negative, got %d", *m)
+	}
+	if m := p.Batching.MaxCost; m != nil && *m < 0 {
+		fail(lineAt(root, "batching", "maxCost"), "batching.maxCost", "must not be negative, got %v", *m)
+	}
+	for model, price := range p.Pricing {
+		if price.Input < 0 || price.Output < 0 {
+			fail(lineAt(root, "pricing", model), "pricing."+model, "price must not be negative")
+		}
+	}
+	if p.References.Max != nil && *p.References.Max < 0 {
+		fail(lineAt(root, "references", "max"), "references.max", "must not be negative, got %d", *p.References.Max)
+	}
+	return errs
+}
+
+// checkFields reports mapping keys that have no matching yaml tag in t.
+func checkFields(file string, n *yaml.Node, t reflect.Type, path string, errs *[]error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	n = resolve(n)
+	if n == nil {
+		return
+	}
+	// Merged mappings hold fields of the mapping they are merged into.
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if isMerge(n.Content[i]) {
+				for _, m := range merged(n.Content[i+1]) {
+					checkFields(file, m, t, path, errs)
+				}
+			}
+		}
+	}
+	switch {
+	case n.Kind == yaml.SequenceNode && t.Kind() == reflect.Slice:
+		for i, item := range n.Content {
+			checkFields(file, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if !isMerge(n.Content[i]) {
+				checkFields(file, n.Content[i+1], t.Elem(), join(path, n.Content[i].Value), errs)
+			}
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			if isMerge(key) {
+				continue
+			}
+			ft, ok := fields[key.Value]
+			if !ok {
+				*errs = append(*errs, FieldError{File: file, Line: key.Line, Field: join(path, key.Value), Msg: "unknown field"})
+				continue
+			}
+			checkFields(file, n.Content[i+1], ft, join(path, key.Value), errs)
+		}
+	}
+}
+
+var yamlLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlErrors turns yaml.v3 errors into FieldErrors so every message has
+// the same file:line shape.
+func yamlErrors(file string, err error) error {
+	var msgs []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+	errs := make([]error, 0, len(msgs))
+	for _, m := range msgs {
+		if sub := yamlLineRe.FindStringSubmatch(m); sub != nil {
+			line, _ := strconv.Atoi(sub[1])
+			errs = append(errs, FieldError{File: file, Line: line, Msg: sub[2]})
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", file, m))
+	}
+	return errors.Join(errs...)
+}
+
+// lookup returns the value node of key in a mapping node.
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key && !isMerge(n.Content[i]) {
+			return resolve(n.Content[i+1])
+		}
+	}
+	// Keys of the mapping itself win over merged ones.
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if !isMerge(n.Content[i]) {
+			continue
+		}
+		for _, m := range merged(n.Content[i+1]) {
+			if v := lookup(m, key); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// lineAt returns the line of the value at path below n, where a path
+// element is a mapping key or a sequence index. When the path is not in
+// the tree it returns the line of the deepest node on the way.
+func lineAt(n *yaml.Node, path ...any) int {
+	line := 0
+	if n != nil {
+		line = n.Line
+	}
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			n = lookup(n, p)
+		case int:
+			if n = resolve(n); n == nil || n.Kind != yaml.SequenceNode || p >= len(n.Content) {
+				return line
+			}
+			n = n.Content[p]
+		}
+		if n == nil {
+			return line
+		}
+		line = n.Line
+	}
+	return line
+}
+
+// resolve follows an alias to its anchored node.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// isMerge reports whether key is the "<<" merge key.
+func isMerge(key *yaml.Node) bool {
+	return key.Kind == yaml.ScalarNode && key.Value == "<<" && (key.Tag == "!!merge" || key.Tag == "")
+}
+
+// merged returns the mappings a merge key's value pulls in: a mapping, an
+// alias of one, or a sequence of them.
+func merged(v *yaml.Node) []*yaml.Node {
+	v = resolve(v)
+	if v == nil {
+		return nil
+	}
+	if v.Kind == yaml.SequenceNode {
+		var out []*yaml.Node
+		for _, item := range v.Content {
+			out = append(out, merged(item)...)
+		}
+		return out
+	}
+	return []*yaml.Node{v}
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
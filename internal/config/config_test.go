@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_YAML(t *testing.T) {
+	src := `locales: [fr, de]
+paths:
+  localesDir: src/i18n
+  localePattern: "{locale}/common.json"
+model: gpt-4
+batching:
+  size: 50
+glossary:
+  - term: nogodey
+    note: brand name
+localeOptions:
+  de:
+    instructions: Always use "du".
+`
+	p, err := Parse("nogodey.yaml", []byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fr", "de"}, p.Locales)
+	assert.Equal(t, "src/i18n", p.Paths.LocalesDir)
+	require.NotNil(t, p.Batching.Size)
+	assert.Equal(t, 50, *p.Batching.Size)
+	assert.Nil(t, p.Batching.MaxRetries)
+	assert.Equal(t, "nogodey", p.Glossary[0].Term)
+	assert.Equal(t, `Always use "du".`, p.LocaleOptions["de"].Instructions)
+}
+
+func TestParse_JSON(t *testing.T) {
+	src := `{
+  "locales": ["pidgin"],
+  "batching": {"maxRetries": 5}
+}`
+	p, err := Parse("nogodey.json", []byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pidgin"}, p.Locales)
+	assert.Equal(t, 5, *p.Batching.MaxRetries)
+}
+
+func TestParse_AliasesAndMergeKeys(t *testing.T) {
+	_, err := Parse("nogodey.yaml", []byte("locales: [fr]\n<<: {provider: bogus}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:2: provider: unknown provider \"bogus\"")
+
+	src := `locales: [fr, ""]
+batching: {maxTokens: &negative -1, maxCost: *negative}
+candidates:
+  count: 0
+length:
+  <<: {fontSize: -1}
+  ratios: {title: -1}
+`
+	_, err = Parse("nogodey.yaml", []byte(src))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:1: locales[1]: locale must not be empty")
+	assert.Contains(t, err.Error(), "nogodey.yaml:2: batching.maxCost: must not be negative")
+	assert.Contains(t, err.Error(), "nogodey.yaml:4: candidates.count: must be at least 1")
+	assert.Contains(t, err.Error(), "nogodey.yaml:6: length.fontSize: must not be negative")
+	assert.Contains(t, err.Error(), "nogodey.yaml:7: length.ratios.title: ratio must not be negative")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [fr]\nlength:\n  ratios: &ratios {title: 2}\n  keys:\n    save: {<<: *ratios}\n"))
+	assert.ErrorContains(t, err, "nogodey.yaml:3: length.keys.save.title: unknown field", "merged fields are checked too")
+
+	p, err := Parse("nogodey.yaml", []byte("locales: &l [fr]\nreferences: {locales: *l}\nlength: {<<: {fontSize: 14}}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fr"}, p.References.Locales)
+	assert.Equal(t, 14.0, p.Length.FontSize)
+}
+
+func TestParse_ErrorsHaveLineNumbers(t *testing.T) {
+	src := `locales: [fr]
+batching:
+  sise: 10
+  maxRetries: many
+provider: deepl
+`
+	_, err := Parse("nogodey.yaml", []byte(src))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:3: batching.sise: unknown field")
+	assert.Contains(t, err.Error(), "nogodey.yaml:4:")
+
+	_, err = Parse("nogodey.yaml", []byte("provider: deepl\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:1: provider: unknown provider")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [fr]\nlength:\n  ratios:\n    title: -1\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:4: length.ratios.title: ratio must not be negative")
+	_, err = Parse("nogodey.yaml", []byte("locales: [fr]\nlength:\n  ratios:\n    title: 0\n"))
+	assert.NoError(t, err, "0 turns a ratio off")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [fr]\nlength:\n  fontSize: -2\n  keys:\n    save: {maxWidth: -1}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:3: length.fontSize: must not be negative")
+	assert.Contains(t, err.Error(), "nogodey.yaml:5: length.keys.save: budget must not be negative")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [fr]\ncandidates:\n  keys: [\"paywall-[\"]\n  count: 0\n  temperatures: [0.5, 3]\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:3: candidates.keys[0]: invalid pattern")
+	assert.Contains(t, err.Error(), "nogodey.yaml:4: candidates.count: must be at least 1")
+	assert.Contains(t, err.Error(), "nogodey.yaml:5: candidates.temperatures[1]: must be between 0 and 2")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [fr]\nbatching:\n  maxCost: -5\npricing:\n  gpt-4o: {input: -1, output: 10}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:3: batching.maxCost: must not be negative")
+	assert.Contains(t, err.Error(), "nogodey.yaml:5: pricing.gpt-4o: price must not be negative")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [de]\nprovider: exec\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:2: provider: the exec provider needs exec.command")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [en-XA]\npseudo:\n  ratio: 0.5\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:3: pseudo.ratio: must be at least 1")
+
+	_, err = Parse("nogodey.yaml", []byte("locales: [fr]\nreferences:\n  max: -1\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nogodey.yaml:3: references.max: must not be negative")
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "", Find(dir))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nogodey.json"), []byte("{}"), 0o644))
+	assert.Equal(t, filepath.Join(dir, "nogodey.json"), Find(dir))
+}
@@ -0,0 +1,218 @@
+// Package font reads the parts of TrueType and OpenType fonts that
+// nogodey needs to check translations against the app's fonts: which
+// characters a font can render and how wide text set in it is.
+package font
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode"
+)
+
+// Font is a parsed font file.
+type Font struct {
+	// Name is the file name, for reports.
+	Name string
+	// glyphs maps every character with a glyph to its glyph index.
+	glyphs  map[rune]uint16
+	metrics *metrics
+	// metricsErr is why the metrics could not be read. It only fails
+	// Width, so the font can still be checked for missing glyphs.
+	metricsErr error
+}
+
+// Load reads a .ttf or .otf file. For a .ttc collection the first font
+// is used.
+func Load(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading font: %w", err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f.Name = filepath.Base(path)
+	return f, nil
+}
+
+var errShort = errors.New("unexpected end of font data")
+
+// Parse reads a font from memory.
+func Parse(data []byte) (*Font, error) {
+	offset := 0
+	if len(data) >= 16 && string(data[:4]) == "ttcf" {
+		offset = int(u32(data, 12))
+	}
+	tables, err := readTables(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	cmap, ok := tables["cmap"]
+	if !ok {
+		return nil, errors.New("font has no cmap table")
+	}
+	glyphs, err := parseCmap(cmap)
+	if err != nil {
+		return nil, fmt.Errorf("cmap: %w", err)
+	}
+	m, err := parseMetrics(tables)
+	return &Font{glyphs: glyphs, metrics: m, metricsErr: err}, nil
+}
+
+// readTables reads the table directory of the font starting at offset.
+func readTables(data []byte, offset int) (map[string][]byte, error) {
+	if len(data) < offset+12 {
+		return nil, errShort
+	}
+	switch v := u32(data, offset); v {
+	case 0x00010000, 0x74727565, 0x4F54544F: // 1.0, "true", "OTTO"
+	default:
+		return nil, fmt.Errorf("not a TrueType or OpenType font (version %#x)", v)
+	}
+	n := int(u16(data, offset+4))
+	if len(data) < offset+12+16*n {
+		return nil, errShort
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		rec := offset + 12 + 16*i
+		tag := string(data[rec : rec+4])
+		start, length := int(u32(data, rec+8)), int(u32(data, rec+12))
+		if start+length > len(data) || start+length < start {
+			return nil, fmt.Errorf("table %q lies outside the file", tag)
+		}
+		tables[tag] = data[start : start+length]
+	}
+	return tables, nil
+}
+
+// parseCmap merges every Unicode subtable of a cmap table.
+func parseCmap(t []byte) (map[rune]uint16, error) {
+	if len(t) < 4 {
+		return nil, errShort
+	}
+	n := int(u16(t, 2))
+	if len(t) < 4+8*n {
+		return nil, errShort
+	}
+	glyphs := make(map[rune]uint16)
+	found := false
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		platform, encoding := u16(t, rec), u16(t, rec+2)
+		unicode := platform == 0 || platform == 3 && (encoding == 1 || encoding == 10)
+		if !unicode {
+			continue
+		}
+		sub := int(u32(t, rec+4))
+		if sub+2 > len(t) {
+			return nil, errShort
+		}
+		var err error
+		switch format := u16(t, sub); format {
+		case 4:
+			err = parseFormat4(t[sub:], glyphs)
+		case 12:
+			err = parseFormat12(t[sub:], glyphs)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, errors.New("no Unicode subtable in format 4 or 12")
+	}
+	return glyphs, nil
+}
+
+// parseFormat4 reads a segment mapping to delta values, the BMP table
+// every TrueType font has.
+func parseFormat4(t []byte, glyphs map[rune]uint16) error {
+	if len(t) < 14 {
+		return errShort
+	}
+	segs := int(u16(t, 6)) / 2
+	ends, starts := 14, 16+2*segs
+	deltas, rangeOffsets := starts+2*segs, starts+4*segs
+	if len(t) < rangeOffsets+2*segs {
+		return errShort
+	}
+	for i := 0; i < segs; i++ {
+		end, start := rune(u16(t, ends+2*i)), rune(u16(t, starts+2*i))
+		delta, ro := u16(t, deltas+2*i), int(u16(t, rangeOffsets+2*i))
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var g uint16
+			if ro == 0 {
+				g = uint16(c) + delta
+			} else {
+				at := rangeOffsets + 2*i + ro + 2*int(c-start)
+				if at+2 > len(t) {
+					return errShort
+				}
+				if g = u16(t, at); g != 0 {
+					g += delta
+				}
+			}
+			if g != 0 {
+				glyphs[c] = g
+			}
+		}
+	}
+	return nil
+}
+
+// parseFormat12 reads segmented coverage, used for characters outside
+// the BMP such as emoji.
+func parseFormat12(t []byte, glyphs map[rune]uint16) error {
+	if len(t) < 16 {
+		return errShort
+	}
+	n := int(u32(t, 12))
+	if len(t) < 16+12*n {
+		return errShort
+	}
+	mapped := 0
+	for i := 0; i < n; i++ {
+		g := 16 + 12*i
+		start, end, glyph := u32(t, g), u32(t, g+4), u32(t, g+8)
+		if start > end || end > unicode.MaxRune {
+			return fmt.Errorf("invalid group %d", i)
+		}
+		// Groups may overlap, so a font could repeat the whole range
+		// over and over; no valid font maps more than every code point.
+		if mapped += int(end-start) + 1; mapped > maxMapped {
+			return fmt.Errorf("groups map more than %d characters", maxMapped)
+		}
+		for c := start; c <= end; c++ {
+			if id := glyph + (c - start); id != 0 && id <= 0xFFFF {
+				glyphs[rune(c)] = uint16(id)
+			}
+		}
+	}
+	return nil
+}
+
+// maxMapped is the number of code points in Unicode.
+const maxMapped = unicode.MaxRune + 1
+
+// GlyphIndex returns the glyph for r, if the font has one.
+func (f *Font) GlyphIndex(r rune) (uint16, bool) {
+	g, ok := f.glyphs[r]
+	return g, ok
+}
+
+// Has reports whether the font can render r.
+func (f *Font) Has(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+func u16(b []byte, i int) uint16 { return binary.BigEndian.Uint16(b[i:]) }
+func u32(b []byte, i int) uint32 { return binary.BigEndian.Uint32(b[i:]) }
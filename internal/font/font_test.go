@@ -0,0 +1,112 @@
+package font
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/font/fonttest"
+)
+
+// cmap4 builds a cmap table with a format 4 subtable: 'A'-'C' mapped by
+// delta to glyphs 1-3, 'x' mapped through the glyph array to glyph 9,
+// and the required 0xFFFF end segment.
+func cmap4() []byte {
+	be := binary.BigEndian
+	u := func(vs ...uint16) []byte {
+		b := make([]byte, 2*len(vs))
+		for i, v := range vs {
+			be.PutUint16(b[2*i:], v)
+		}
+		return b
+	}
+	var sub []byte
+	sub = append(sub, u(4, 0, 0, 6, 0, 0, 0)...) // format, length, language, segCountX2, search fields
+	sub = append(sub, u('C', 'x', 0xFFFF)...)    // endCode
+	sub = append(sub, u(0)...)                   // reservedPad
+	sub = append(sub, u('A', 'x', 0xFFFF)...)    // startCode
+	sub = append(sub, u(0x10000+1-'A', 0, 1)...) // idDelta
+	sub = append(sub, u(0, 4, 0)...)             // idRangeOffset: 'x' → glyphIdArray[0]
+	sub = append(sub, u(9)...)                   // glyphIdArray
+	be.PutUint16(sub[2:], uint16(len(sub)))
+	head := u(0, 1, 3, 1, 0, 12)
+	return append(head, sub...)
+}
+
+func TestParse_Format12(t *testing.T) {
+	f, err := Parse(fonttest.SFNT(map[string][]byte{"cmap": fonttest.Cmap(map[rune]uint16{'a': 1, 'ạ': 2, '🎉': 3})}))
+	require.NoError(t, err)
+	assert.True(t, f.Has('a'))
+	assert.True(t, f.Has('🎉'))
+	assert.False(t, f.Has('b'))
+	g, ok := f.GlyphIndex('ạ')
+	assert.True(t, ok)
+	assert.Equal(t, uint16(2), g)
+}
+
+// cmap12 builds a cmap table with a format 12 subtable of raw groups,
+// each start, end and first glyph.
+func cmap12(groups ...[3]uint32) []byte {
+	be := binary.BigEndian
+	sub := make([]byte, 16+12*len(groups))
+	be.PutUint16(sub, 12)
+	be.PutUint32(sub[4:], uint32(len(sub)))
+	be.PutUint32(sub[12:], uint32(len(groups)))
+	for i, g := range groups {
+		be.PutUint32(sub[16+12*i:], g[0])
+		be.PutUint32(sub[20+12*i:], g[1])
+		be.PutUint32(sub[24+12*i:], g[2])
+	}
+	head := make([]byte, 12)
+	be.PutUint16(head[2:], 1)
+	be.PutUint16(head[4:], 3)
+	be.PutUint16(head[6:], 10)
+	be.PutUint32(head[8:], 12)
+	return append(head, sub...)
+}
+
+func TestParse_Format12Malformed(t *testing.T) {
+	full := [3]uint32{0, 0x10FFFF, 1}
+	for name, cmap := range map[string][]byte{
+		"past last code point": cmap12([3]uint32{0x7FFFFFFF, 0x7FFFFFFF, 1}),
+		"end before start":     cmap12([3]uint32{'b', 'a', 1}),
+		"repeated full range":  cmap12(full, full, full, full),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(fonttest.SFNT(map[string][]byte{"cmap": cmap}))
+			assert.ErrorContains(t, err, "cmap:")
+		})
+	}
+
+	f, err := Parse(fonttest.SFNT(map[string][]byte{"cmap": cmap12([3]uint32{'a', 'c', 1}, [3]uint32{0x10FFFF, 0x10FFFF, 4})}))
+	require.NoError(t, err)
+	assert.True(t, f.Has('c'))
+	assert.True(t, f.Has(0x10FFFF))
+}
+
+func TestParse_Format4(t *testing.T) {
+	f, err := Parse(fonttest.SFNT(map[string][]byte{"cmap": cmap4()}))
+	require.NoError(t, err)
+	for r, want := range map[rune]uint16{'A': 1, 'B': 2, 'C': 3, 'x': 9} {
+		g, ok := f.GlyphIndex(r)
+		assert.True(t, ok, string(r))
+		assert.Equal(t, want, g, string(r))
+	}
+	assert.False(t, f.Has('D'))
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "App.ttf")
+	require.NoError(t, os.WriteFile(path, fonttest.SFNT(map[string][]byte{"cmap": fonttest.Cmap(map[rune]uint16{'a': 1})}), 0o644))
+	f, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "App.ttf", f.Name)
+
+	_, err = Parse([]byte("not a font at all"))
+	assert.Error(t, err)
+	_, err = Parse(fonttest.SFNT(map[string][]byte{"head": make([]byte, 54)}))
+	assert.ErrorContains(t, err, "no cmap table")
+}
@@ -0,0 +1,108 @@
+// Package fonttest builds minimal font files for tests.
+package fonttest
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// SFNT assembles a font file from raw tables keyed by tag.
+func SFNT(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	be := binary.BigEndian
+	out := make([]byte, 12+16*len(tags))
+	be.PutUint32(out, 0x00010000)
+	be.PutUint16(out[4:], uint16(len(tags)))
+	for i, tag := range tags {
+		rec := out[12+16*i:]
+		copy(rec, tag)
+		be.PutUint32(rec[8:], uint32(len(out)))
+		be.PutUint32(rec[12:], uint32(len(tables[tag])))
+		out = append(out, tables[tag]...)
+	}
+	return out
+}
+
+// Cmap builds a cmap table with a single format 12 subtable.
+func Cmap(glyphs map[rune]uint16) []byte {
+	chars := make([]rune, 0, len(glyphs))
+	for r := range glyphs {
+		chars = append(chars, r)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+	be := binary.BigEndian
+	sub := make([]byte, 16+12*len(chars))
+	be.PutUint16(sub, 12)
+	be.PutUint32(sub[4:], uint32(len(sub)))
+	be.PutUint32(sub[12:], uint32(len(chars)))
+	for i, r := range chars {
+		g := sub[16+12*i:]
+		be.PutUint32(g, uint32(r))
+		be.PutUint32(g[4:], uint32(r))
+		be.PutUint32(g[8:], uint32(glyphs[r]))
+	}
+	head := make([]byte, 12)
+	be.PutUint16(head[2:], 1)
+	be.PutUint16(head[4:], 3)
+	be.PutUint16(head[6:], 10)
+	be.PutUint32(head[8:], 12)
+	return append(head, sub...)
+}
+
+// Chars maps every character of s to a glyph, numbered from 1 in order of
+// first appearance.
+func Chars(s string) map[rune]uint16 {
+	glyphs := make(map[rune]uint16)
+	for _, r := range s {
+		if _, ok := glyphs[r]; !ok {
+			glyphs[r] = uint16(len(glyphs) + 1)
+		}
+	}
+	return glyphs
+}
+
+// Metrics builds the head, hhea and hmtx tables for glyphs with the given
+// advance widths, indexed by glyph.
+func Metrics(unitsPerEm uint16, advances []uint16) map[string][]byte {
+	be := binary.BigEndian
+	head := make([]byte, 54)
+	be.PutUint32(head, 0x00010000)
+	be.PutUint16(head[18:], unitsPerEm)
+	hhea := make([]byte, 36)
+	be.PutUint32(hhea, 0x00010000)
+	be.PutUint16(hhea[34:], uint16(len(advances)))
+	hmtx := make([]byte, 4*len(advances))
+	for i, a := range advances {
+		be.PutUint16(hmtx[4*i:], a)
+	}
+	return map[string][]byte{"head": head, "hhea": hhea, "hmtx": hmtx}
+}
+
+// Kern builds a kern table with one horizontal format 0 subtable.
+func Kern(pairs map[[2]uint16]int16) []byte {
+	keys := make([][2]uint16, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+	be := binary.BigEndian
+	sub := make([]byte, 14+6*len(keys))
+	be.PutUint16(sub[2:], uint16(len(sub)))
+	be.PutUint16(sub[4:], 1) // horizontal, format 0
+	be.PutUint16(sub[6:], uint16(len(keys)))
+	for i, k := range keys {
+		p := sub[14+6*i:]
+		be.PutUint16(p, k[0])
+		be.PutUint16(p[2:], k[1])
+		be.PutUint16(p[4:], uint16(pairs[k]))
+	}
+	head := make([]byte, 4)
+	be.PutUint16(head[2:], 1)
+	return append(head, sub...)
+}
@@ -0,0 +1,133 @@
+package font
+
+import (
+	"errors"
+	"fmt"
+)
+
+// metrics are the horizontal metrics used to measure text.
+type metrics struct {
+	unitsPerEm uint16
+	// advances holds the advance width of each glyph with its own metric;
+	// later glyphs share the last one, as in the hmtx table.
+	advances []uint16
+	// kern adjusts the advance of the left glyph of a pair.
+	kern map[[2]uint16]int16
+}
+
+// parseMetrics reads the head, hhea, hmtx and kern tables. A font without
+// head, hhea or hmtx has no metrics and cannot be measured.
+func parseMetrics(tables map[string][]byte) (*metrics, error) {
+	head, hhea, hmtx := tables["head"], tables["hhea"], tables["hmtx"]
+	if head == nil || hhea == nil || hmtx == nil {
+		return nil, nil
+	}
+	if len(head) < 20 || len(hhea) < 36 {
+		return nil, errShort
+	}
+	m := &metrics{unitsPerEm: u16(head, 18)}
+	if m.unitsPerEm == 0 {
+		return nil, errors.New("head: unitsPerEm is zero")
+	}
+	n := int(u16(hhea, 34))
+	if n == 0 || len(hmtx) < 4*n {
+		return nil, fmt.Errorf("hmtx: %w", errShort)
+	}
+	m.advances = make([]uint16, n)
+	for i := range m.advances {
+		m.advances[i] = u16(hmtx, 4*i)
+	}
+	if kern := tables["kern"]; kern != nil {
+		pairs, err := parseKern(kern)
+		if err != nil {
+			return nil, fmt.Errorf("kern: %w", err)
+		}
+		m.kern = pairs
+	}
+	return m, nil
+}
+
+// parseKern reads the horizontal format 0 subtables of an OpenType kern
+// table. Apple's version 1 tables are ignored.
+func parseKern(t []byte) (map[[2]uint16]int16, error) {
+	if len(t) < 4 {
+		return nil, errShort
+	}
+	if u16(t, 0) != 0 {
+		return nil, nil
+	}
+	pairs := make(map[[2]uint16]int16)
+	at := 4
+	for i := 0; i < int(u16(t, 2)); i++ {
+		if at+6 > len(t) {
+			return nil, errShort
+		}
+		length, coverage := int(u16(t, at+2)), u16(t, at+4)
+		horizontal, minimum, cross := coverage&1 != 0, coverage&2 != 0, coverage&4 != 0
+		if format := coverage >> 8; format == 0 && horizontal && !minimum && !cross {
+			if at+14 > len(t) {
+				return nil, errShort
+			}
+			n := int(u16(t, at+6))
+			if at+14+6*n > len(t) {
+				return nil, errShort
+			}
+			for j := 0; j < n; j++ {
+				p := at + 14 + 6*j
+				pairs[[2]uint16{u16(t, p), u16(t, p+2)}] += int16(u16(t, p+4))
+			}
+		}
+		if length < 6 {
+			return nil, fmt.Errorf("invalid subtable %d", i)
+		}
+		at += length
+	}
+	return pairs, nil
+}
+
+// advance returns the width of glyph g in font units.
+func (m *metrics) advance(g uint16) int {
+	if int(g) < len(m.advances) {
+		return int(m.advances[g])
+	}
+	return int(m.advances[len(m.advances)-1])
+}
+
+// Width returns how wide s renders in pixels at size pixels per em (for
+// an app that sets text in points at 1x, the point size). Each character
+// is drawn from the first font that has it; characters no font has are
+// drawn as the first font's missing-glyph box. Kerning comes from the
+// legacy kern table only, so fonts that kern through GPOS measure a
+// little wide.
+func Width(fonts []*Font, s string, size float64) (float64, error) {
+	if len(fonts) == 0 {
+		return 0, errors.New("no fonts to measure with")
+	}
+	for _, f := range fonts {
+		if f.metricsErr != nil {
+			return 0, fmt.Errorf("font %s: %w", f.Name, f.metricsErr)
+		}
+		if f.metrics == nil {
+			return 0, fmt.Errorf("font %s has no horizontal metrics", f.Name)
+		}
+	}
+	var width float64
+	var prev *Font
+	var prevGlyph uint16
+	for _, r := range s {
+		f, g := fonts[0], uint16(0)
+		for _, candidate := range fonts {
+			if id, ok := candidate.GlyphIndex(r); ok {
+				f, g = candidate, id
+				break
+			}
+		}
+		units := f.metrics.advance(g)
+		if prev == f {
+			width += float64(f.metrics.kern[[2]uint16{prevGlyph, g}]) * size / float64(f.metrics.unitsPerEm)
+		}
+		width += float64(units) * size / float64(f.metrics.unitsPerEm)
+		prev, prevGlyph = f, g
+	}
+	return width, nil
+}
@@ -0,0 +1,52 @@
+package font
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/font/fonttest"
+)
+
+func TestWidth(t *testing.T) {
+	// Glyph 0 is the missing-glyph box; 'A' and 'V' kern together.
+	tables := fonttest.Metrics(1000, []uint16{500, 600, 700, 250})
+	tables["cmap"] = fonttest.Cmap(map[rune]uint16{'A': 1, 'V': 2, ' ': 3})
+	tables["kern"] = fonttest.Kern(map[[2]uint16]int16{{1, 2}: -100})
+	latin, err := Parse(fonttest.SFNT(tables))
+	require.NoError(t, err)
+
+	w, err := Width([]*Font{latin}, "A V", 20)
+	require.NoError(t, err)
+	assert.InDelta(t, 31, w, 1e-9) // (600+250+700) * 20/1000
+	w, err = Width([]*Font{latin}, "AV", 20)
+	require.NoError(t, err)
+	assert.InDelta(t, 24, w, 1e-9, "kerning applies")
+	w, err = Width([]*Font{latin}, "Aж", 20)
+	require.NoError(t, err)
+	assert.InDelta(t, 22, w, 1e-9, "missing characters draw as the box")
+
+	// Glyphs past the hmtx entries share the last advance; a fallback font
+	// is measured in its own units.
+	fallback := fonttest.Metrics(2048, []uint16{1024, 2048})
+	fallback["cmap"] = fonttest.Cmap(map[rune]uint16{'ж': 1, 'щ': 5})
+	cyrillic, err := Parse(fonttest.SFNT(fallback))
+	require.NoError(t, err)
+	w, err = Width([]*Font{latin, cyrillic}, "Aжщ", 20)
+	require.NoError(t, err)
+	assert.InDelta(t, 52, w, 1e-9)
+
+	bare, err := Parse(fonttest.SFNT(map[string][]byte{"cmap": tables["cmap"]}))
+	require.NoError(t, err)
+	_, err = Width([]*Font{bare}, "A", 20)
+	assert.ErrorContains(t, err, "no horizontal metrics")
+
+	broken := maps.Clone(tables)
+	broken["hmtx"] = broken["hmtx"][:6]
+	f, err := Parse(fonttest.SFNT(broken))
+	require.NoError(t, err, "glyph checks still work")
+	assert.True(t, f.Has('V'))
+	_, err = Width([]*Font{f}, "A", 20)
+	assert.ErrorContains(t, err, "hmtx")
+}
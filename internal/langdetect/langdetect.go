@@ -0,0 +1,239 @@
+// Package langdetect flags translations that are not in the language they
+// should be: the source text returned unchanged, text in the wrong script,
+// or a neighbouring language such as Ukrainian for Russian or Chinese for
+// Japanese. The heuristics are deliberately cheap and conservative; they
+// look for clear signals and stay quiet on short strings.
+package langdetect
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/you/nogodey/internal/mask"
+	"golang.org/x/text/language"
+)
+
+// scripts maps ISO 15924 codes to the Unicode scripts text in them uses.
+var scripts = map[string][]string{
+	"Arab": {"Arabic"},
+	"Armn": {"Armenian"},
+	"Beng": {"Bengali"},
+	"Cyrl": {"Cyrillic"},
+	"Deva": {"Devanagari"},
+	"Ethi": {"Ethiopic"},
+	"Geor": {"Georgian"},
+	"Grek": {"Greek"},
+	"Gujr": {"Gujarati"},
+	"Guru": {"Gurmukhi"},
+	"Hang": {"Hangul"},
+	"Hans": {"Han"},
+	"Hant": {"Han"},
+	"Hebr": {"Hebrew"},
+	"Jpan": {"Han", "Hiragana", "Katakana"},
+	"Khmr": {"Khmer"},
+	"Knda": {"Kannada"},
+	"Kore": {"Hangul", "Han"},
+	"Laoo": {"Lao"},
+	"Latn": {"Latin"},
+	"Mlym": {"Malayalam"},
+	"Mymr": {"Myanmar"},
+	"Sinh": {"Sinhala"},
+	"Taml": {"Tamil"},
+	"Telu": {"Telugu"},
+	"Thai": {"Thai"},
+}
+
+// profile describes a language by its commonest short words and the
+// letters that set it apart from its neighbours.
+type profile struct {
+	name      string
+	stopwords []string
+	letters   string
+}
+
+var profiles = map[string]profile{
+	"en": {"English", strings.Fields("the and to of you your is are for with this that it be on not"), ""},
+	"de": {"German", strings.Fields("der die das und ist nicht ein eine zu mit für sie ich auf den"), "äöüß"},
+	"fr": {"French", strings.Fields("le la les et est un une des pour pas vous que du avec sur"), "àâçèéêîôùû"},
+	"es": {"Spanish", strings.Fields("el la los las y es un una para no que por con su del al"), "ñ¿¡"},
+	"pt": {"Portuguese", strings.Fields("o os as e é um uma para não que por com seu do da em"), "ãõç"},
+	"it": {"Italian", strings.Fields("il lo la gli le e è un una per non che di con del della"), "ìò"},
+	"ru": {"Russian", strings.Fields("и в не на что с это как для вы по из к мы все"), "ыэъё"},
+	"uk": {"Ukrainian", strings.Fields("і й та не на що з це як для ви по із до ми всі"), "іїєґ"},
+}
+
+// neighbours are the languages a model is likely to answer in instead of
+// the one asked for, besides the source language, which every locale is
+// compared with.
+var neighbours = map[string][]string{
+	"ru": {"uk"},
+	"uk": {"ru"},
+	"es": {"pt"},
+	"pt": {"es"},
+}
+
+// lookalikes maps languages to the source they share most of their words
+// with, so that comparing the two means nothing: Nigerian Pidgin and
+// English.
+var lookalikes = map[string]string{
+	"pcm": "en",
+}
+
+// minLetters is the shortest text the script check judges.
+const minLetters = 3
+
+// Detector checks translations into one locale.
+type Detector struct {
+	lang   string
+	script string
+	source string
+	allow  []string
+}
+
+// New returns a detector for translations into locale from sourceLocale.
+// Source strings or keys in allowIdentical may be translated as
+// themselves, e.g. brand names.
+func New(locale, sourceLocale string, allowIdentical []string) *Detector {
+	d := &Detector{lang: base(locale), source: base(sourceLocale), allow: allowIdentical}
+	if tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-")); err == nil {
+		if s, conf := tag.Script(); conf != language.No {
+			d.script = s.String()
+		}
+	}
+	return d
+}
+
+func base(locale string) string {
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return strings.ToLower(locale)
+	}
+	b, _ := tag.Base()
+	return b.String()
+}
+
+// Check returns one message per reason the translation of key looks like
+// it is in the wrong language. A nil detector reports nothing.
+func (d *Detector) Check(key, source, translation string) []string {
+	if d == nil {
+		return nil
+	}
+	if msg := d.identical(key, source, translation); msg != "" {
+		return []string{msg}
+	}
+	text := mask.Strip(translation)
+	if msg := d.checkScript(text); msg != "" {
+		return []string{msg}
+	}
+	if msg := d.checkNeighbours(text); msg != "" {
+		return []string{msg}
+	}
+	return nil
+}
+
+// identical flags a translation that repeats a source of two or more
+// words; single words such as "OK" or "Email" are often the same.
+func (d *Detector) identical(key, source, translation string) string {
+	if d.lang == d.source {
+		return ""
+	}
+	s := strings.TrimSpace(mask.Strip(source))
+	if !strings.EqualFold(s, strings.TrimSpace(mask.Strip(translation))) || len(words(s)) < 2 {
+		return ""
+	}
+	if slices.ContainsFunc(d.allow, func(a string) bool { return a == key || strings.EqualFold(a, s) }) {
+		return ""
+	}
+	return "is identical to the source text; translate it"
+}
+
+// checkScript flags text with few letters in the locale's script, and
+// Japanese without kana or Chinese with it. Names and brands in Latin
+// script are common in any language, so a quarter is enough.
+func (d *Detector) checkScript(text string) string {
+	want, ok := scripts[d.script]
+	if !ok {
+		return ""
+	}
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for name, table := range unicode.Scripts {
+			if unicode.Is(table, r) {
+				counts[name]++
+				break
+			}
+		}
+	}
+	if letters < minLetters {
+		return ""
+	}
+	inScript := 0
+	for _, name := range want {
+		inScript += counts[name]
+	}
+	if 4*inScript < letters {
+		dominant := ""
+		for name, n := range counts {
+			if n > counts[dominant] || n == counts[dominant] && name < dominant {
+				dominant = name
+			}
+		}
+		return fmt.Sprintf("is written in %s script, expected %s", dominant, strings.Join(want, "/"))
+	}
+	kana := counts["Hiragana"] + counts["Katakana"]
+	switch {
+	case d.script == "Jpan" && kana == 0 && counts["Han"] >= 4:
+		return "has no kana and looks like Chinese, expected Japanese"
+	case (d.script == "Hans" || d.script == "Hant") && kana > 0:
+		return "contains Japanese kana, expected Chinese"
+	}
+	return ""
+}
+
+// checkNeighbours flags text that matches the profile of a neighbouring
+// language better than the locale's own.
+func (d *Detector) checkNeighbours(text string) string {
+	ws := words(strings.ToLower(text))
+	own := score(profiles[d.lang], ws)
+	candidates := neighbours[d.lang]
+	if d.source != d.lang && lookalikes[d.lang] != d.source && !slices.Contains(candidates, d.source) {
+		candidates = append(slices.Clip(candidates), d.source)
+	}
+	for _, n := range candidates {
+		p := profiles[n]
+		if s := score(p, ws); s >= 2 && s > own {
+			if mine, ok := profiles[d.lang]; ok {
+				return fmt.Sprintf("looks like %s rather than %s", p.name, mine.name)
+			}
+			return fmt.Sprintf("looks like %s", p.name)
+		}
+	}
+	return ""
+}
+
+// score counts the stopwords of p in ws and the words with letters
+// distinctive of p.
+func score(p profile, ws []string) int {
+	n := 0
+	for _, w := range ws {
+		if slices.Contains(p.stopwords, w) {
+			n++
+		}
+		if p.letters != "" && strings.ContainsAny(w, p.letters) {
+			n++
+		}
+	}
+	return n
+}
+
+// words splits s into runs of letters and apostrophes.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' && r != '’' })
+}
@@ -0,0 +1,67 @@
+package langdetect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		locale      string
+		source      string
+		translation string
+		want        string
+	}{
+		{"translated", "de", "Save your changes", "Änderungen speichern", ""},
+		{"identical", "de", "Save your changes", "Save your changes", "is identical to the source text; translate it"},
+		{"identical single word", "de", "Email", "Email", ""},
+		{"identical markup", "fr", "<b>Open</b> {name} now", "Open {name} now", "is identical to the source text; translate it"},
+		{"allowed", "de", "Made with nogodey", "Made with nogodey", ""},
+		{"same language", "en-GB", "Save your changes", "Save your changes", ""},
+		{"wrong script", "ru", "Delete account", "Konto löschen", "is written in Latin script, expected Cyrillic"},
+		{"brand name", "ru", "Open Instagram", "Открыть Instagram", ""},
+		{"russian", "ru", "This is not your account", "Это не ваш аккаунт", ""},
+		{"ukrainian for russian", "ru", "This is not your account", "Це не ваш обліковий запис", "looks like Ukrainian rather than Russian"},
+		{"russian for ukrainian", "uk", "Everything for you", "Всё это для вас", "looks like Russian rather than Ukrainian"},
+		{"chinese for japanese", "ja", "Account settings", "帐户设置中心", "has no kana and looks like Chinese, expected Japanese"},
+		{"japanese", "ja", "Account settings", "アカウント設定", ""},
+		{"japanese for chinese", "zh", "Account settings", "アカウント设置", "contains Japanese kana, expected Chinese"},
+		{"english for french", "fr", "Tap to continue", "Tap the button to continue", "looks like English rather than French"},
+		{"pidgin", "pcm", "Check your email for the link", "Abeg check your email for the link", ""},
+		{"short", "ru", "OK", "OK", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(tt.locale, "en", []string{"Made with nogodey"})
+			got := d.Check("key", tt.source, tt.translation)
+			if tt.want == "" {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, []string{tt.want}, got)
+			}
+		})
+	}
+
+	d := New("de", "en", []string{"brand"})
+	assert.Empty(t, d.Check("brand", "Powered by nogodey", "Powered by nogodey"), "keys can be allowed too")
+}
+
+func TestCheck_SourceLocale(t *testing.T) {
+	d := New("it", "de", nil)
+	assert.Equal(t, []string{"looks like German rather than Italian"},
+		d.Check("key", "Speichern Sie die Änderungen", "Speichern Sie die Änderungen für den Export"))
+	assert.Empty(t, d.Check("key", "Open the file", "Open the file with the editor"), "English is not the source")
+
+	d = New("en", "fr", nil)
+	assert.Equal(t, []string{"looks like French rather than English"},
+		d.Check("key", "Enregistrer", "Enregistrer les modifications pour vous"))
+
+	d = New("pt", "es", nil)
+	assert.Equal(t, []string{"looks like Spanish rather than Portuguese"},
+		d.Check("key", "Guardar", "Guardar los cambios para el usuario"), "a neighbour that is also the source is checked once")
+
+	d = New("pcm", "fr", nil)
+	assert.Equal(t, []string{"looks like French"}, d.Check("key", "Enregistrer", "Enregistrer les modifications pour vous"))
+}
@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Cassette modes, selected with NOGODEY_LLM_MODE.
+const (
+	// ModeRecord passes calls on to the provider and saves every answer.
+	ModeRecord = "record"
+	// ModeReplay answers from the cassette and never calls a provider.
+	ModeReplay = "replay"
+)
+
+// ErrNotRecorded is returned in replay mode for a request the cassette
+// has no answer to.
+var ErrNotRecorded = errors.New("request not recorded in cassette")
+
+// Interaction is a recorded request and the response it got. The request
+// is kept so cassettes can be read and reviewed.
+type Interaction struct {
+	Request  cassetteRequest               `json:"request"`
+	Response openai.ChatCompletionResponse `json:"response"`
+}
+
+// cassetteRequest is the part of a request that decides its answer.
+type cassetteRequest struct {
+	Model       string            `json:"model"`
+	Temperature float32           `json:"temperature"`
+	MaxTokens   int               `json:"max_tokens"`
+	Messages    []cassetteMessage `json:"messages"`
+}
+
+type cassetteMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Cassette holds recorded LLM interactions keyed by RequestKey, so sync
+// runs can be repeated offline and deterministically.
+type Cassette struct {
+	Path string
+	Mode string
+
+	mu           sync.Mutex
+	interactions map[string]Interaction
+}
+
+// LoadCassette opens the cassette at path in mode. A missing file is an
+// empty cassette when recording and an error when replaying.
+func LoadCassette(path, mode string) (*Cassette, error) {
+	if mode != ModeRecord && mode != ModeReplay {
+		return nil, fmt.Errorf("unknown cassette mode %q, want %s or %s", mode, ModeRecord, ModeReplay)
+	}
+	c := &Cassette{Path: path, Mode: mode, interactions: make(map[string]Interaction)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && mode == ModeRecord:
+		return c, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("cassette %s not found, record it first with NOGODEY_LLM_MODE=%s", path, ModeRecord)
+	case err != nil:
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	if c.interactions == nil {
+		c.interactions = make(map[string]Interaction)
+	}
+	return c, nil
+}
+
+// Len returns the number of recorded interactions.
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.interactions)
+}
+
+// Client returns a ChatClient that records the calls made to next, or
+// replays them without calling next, depending on the cassette's mode.
+// next may be nil when replaying.
+func (c *Cassette) Client(next ChatClient) ChatClient {
+	return cassetteClient{cassette: c, next: next}
+}
+
+type cassetteClient struct {
+	cassette *Cassette
+	next     ChatClient
+}
+
+func (cc cassetteClient) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	c := cc.cassette
+	key := RequestKey(req)
+	if c.Mode == ModeReplay {
+		c.mu.Lock()
+		in, ok := c.interactions[key]
+		c.mu.Unlock()
+		if !ok {
+			return openai.ChatCompletionResponse{}, fmt.Errorf("%w: %s (model %s), record it again with NOGODEY_LLM_MODE=%s", ErrNotRecorded, key, req.Model, ModeRecord)
+		}
+		return in.Response, nil
+	}
+
+	resp, err := cc.next.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions[key] = Interaction{Request: normalize(req), Response: resp}
+	if err := c.save(); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// save writes the cassette; callers hold c.mu. Keys are sorted by the
+// JSON encoder, so re-recording only changes what changed.
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(c.Path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing cassette %s: %w", c.Path, err)
+	}
+	return nil
+}
+
+// RequestKey hashes the normalized request: model, sampling settings and
+// messages, with line endings and surrounding whitespace of each message
+// ignored.
+func RequestKey(req openai.ChatCompletionRequest) string {
+	data, _ := json.Marshal(normalize(req))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+func normalize(req openai.ChatCompletionRequest) cassetteRequest {
+	r := cassetteRequest{Model: req.Model, Temperature: req.Temperature, MaxTokens: req.MaxTokens}
+	for _, m := range req.Messages {
+		content := strings.TrimSpace(strings.ReplaceAll(m.Content, "\r\n", "\n"))
+		r.Messages = append(r.Messages, cassetteMessage{Role: m.Role, Content: content})
+	}
+	return r
+}
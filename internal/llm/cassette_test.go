@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+)
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "sync.json")
+	resp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"save": "Speichern"}`}}}}
+	req := Request{Model: "gpt-test", Prompt: "Translate save"}
+
+	rec, err := LoadCassette(path, ModeRecord)
+	require.NoError(t, err)
+	got, err := Call(logger.New(), rec.Client(stubClient{resp: resp}), req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"save": "Speichern"}, got)
+	_, err = Call(logger.New(), rec.Client(stubClient{err: errors.New("rate limited")}), Request{Model: "gpt-test", Prompt: "other"})
+	require.Error(t, err)
+	assert.Equal(t, 1, rec.Len(), "failed calls are not recorded")
+
+	play, err := LoadCassette(path, ModeReplay)
+	require.NoError(t, err)
+	client := play.Client(nil)
+	req.Prompt = "Translate save\r\n"
+	got, err = Call(logger.New(), client, req)
+	require.NoError(t, err, "trailing whitespace does not change the key")
+	assert.Equal(t, map[string]string{"save": "Speichern"}, got)
+
+	req.Model = "gpt-other"
+	_, err = Call(logger.New(), client, req)
+	require.ErrorIs(t, err, ErrNotRecorded)
+}
+
+func TestLoadCassette_Errors(t *testing.T) {
+	_, err := LoadCassette(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NOGODEY_LLM_MODE=record")
+
+	_, err = LoadCassette("x.json", "rewind")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown cassette mode "rewind"`)
+}
+
+func TestRequestKey(t *testing.T) {
+	req := openai.ChatCompletionRequest{Model: "m", Messages: []openai.ChatCompletionMessage{{Role: "user", Content: "hi"}}}
+	other := req
+	other.Temperature = 0.9
+	assert.Len(t, RequestKey(req), 32)
+	assert.NotEqual(t, RequestKey(req), RequestKey(other))
+}
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DataItem is one string of a prompt's <data> block. The syncer writes
+// the block from DataItems and providers that translate the data directly
+// instead of reading the prompt, such as an external command, read them
+// back with ReadData.
+type DataItem struct {
+	// Key is the message key. In the data block it is the key of the
+	// item's object and left empty here.
+	Key string `json:"key,omitempty"`
+	// Text is the string to translate. When Source is set, Text is an
+	// existing translation, of a parent or pivot locale, to adapt and
+	// Source is the original.
+	Text   string `json:"text"`
+	Source string `json:"source,omitempty"`
+	// References are translations of the key into other locales.
+	References map[string]string `json:"references,omitempty"`
+	// MaxLength is the length budget of the translation in characters.
+	MaxLength int `json:"maxLength,omitempty"`
+}
+
+// ReadData returns the items of the <data> block of prompt in their
+// order.
+func ReadData(prompt string) ([]DataItem, error) {
+	// The instructions before the block mention it too; the JSON in it
+	// cannot contain a tag, its "<" is escaped.
+	start := strings.LastIndex(prompt, "<data>")
+	if start < 0 {
+		return nil, errors.New("the prompt has no <data> block")
+	}
+	data, _, ok := strings.Cut(prompt[start+len("<data>"):], "</data>")
+	if !ok {
+		return nil, errors.New("the <data> block is not closed")
+	}
+
+	dec := json.NewDecoder(strings.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, errors.New("the <data> block is not a JSON object")
+	}
+	var items []DataItem
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("reading the data block: %w", err)
+		}
+		key := t.(string)
+		var it DataItem
+		if err := dec.Decode(&it); err != nil {
+			return nil, fmt.Errorf("reading the data block: %s: %w", key, err)
+		}
+		it.Key = key
+		items = append(items, it)
+	}
+	return items, nil
+}
@@ -28,7 +28,7 @@ func TestCall_ParsesTranslations(t *testing.T) {
 	resp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: content}}}}
 	client := stubClient{resp: resp}
 	log := logger.New()
-	got, err := Call(log, client, "prompt", "model")
+	got, err := Call(log, client, Request{Model: "model", Prompt: "prompt"})
 	require.NoError(t, err)
 	assert.Equal(t, map[string]string{"a": "A", "b": "B"}, got)
 }
@@ -37,12 +37,74 @@ func TestCall_ParseFailure(t *testing.T) {
 	content := "nothing parsable"
 	resp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: content}}}}
 	client := stubClient{resp: resp}
-	_, err := Call(logger.New(), client, "p", "m")
+	_, err := Call(logger.New(), client, Request{Model: "m", Prompt: "p"})
 	require.Error(t, err)
 }
 
 func TestCall_ClientErrorPropagates(t *testing.T) {
 	client := stubClient{err: errors.New("boom")}
-	_, err := Call(logger.New(), client, "p", "m")
+	_, err := Call(logger.New(), client, Request{Model: "m", Prompt: "p"})
+	require.Error(t, err)
+}
+
+type capturingClient struct{ req *openai.ChatCompletionRequest }
+
+func (c capturingClient) CreateChatCompletion(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	*c.req = req
+	return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `a: "A"`}}}}, nil
+}
+
+func TestCall_AppendsSystemInstructions(t *testing.T) {
+	var req openai.ChatCompletionRequest
+	_, err := Call(logger.New(), capturingClient{&req}, Request{Model: "m", Prompt: "p", System: "Style guide for German:\n- Tone: warm"})
+	require.NoError(t, err)
+	require.Len(t, req.Messages, 2)
+	assert.Equal(t, DefaultSystemPrompt+"\n\nStyle guide for German:\n- Tone: warm", req.Messages[0].Content)
+	assert.Equal(t, "p", req.Messages[1].Content)
+
+	_, err = Call(logger.New(), capturingClient{&req}, Request{Model: "m", Prompt: "p"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSystemPrompt, req.Messages[0].Content)
+}
+
+func TestCall_ParsesJSON(t *testing.T) {
+	content := "```json\n{\"a\": \"Sagt \\\"Hallo\\\"\", \"b\": \"{count} Dateien\\nneu\"}\n```"
+	resp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: content}}}}
+	got, err := Call(logger.New(), stubClient{resp: resp}, Request{Model: "m", Prompt: "p"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": `Sagt "Hallo"`, "b": "{count} Dateien\nneu"}, got)
+
+	// A brace inside a line-format value is not mistaken for JSON.
+	resp.Choices[0].Message.Content = `a: "{count} files"`
+	got, err = Call(logger.New(), stubClient{resp: resp}, Request{Model: "m", Prompt: "p"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "{count} files"}, got)
+}
+
+func TestCallJSON(t *testing.T) {
+	content := "Here you go:\n```json\n{\"a\": [{\"n\": 1}], \"b\": []}\n```"
+	resp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: content}}}}
+	var got map[string][]struct{ N int }
+	require.NoError(t, CallJSON(logger.New(), stubClient{resp: resp}, Request{Model: "m", Prompt: "p"}, &got))
+	assert.Len(t, got["a"], 1)
+	assert.Equal(t, 1, got["a"][0].N)
+	assert.Empty(t, got["b"])
+
+	resp.Choices[0].Message.Content = "no object here"
+	assert.ErrorContains(t, CallJSON(logger.New(), stubClient{resp: resp}, Request{Model: "m", Prompt: "p"}, &got), "no JSON object")
+
+	var req openai.ChatCompletionRequest
+	var ignored map[string]string
+	_ = CallJSON(logger.New(), capturingClient{&req}, Request{Model: "m", Prompt: "p", BasePrompt: "You review translations.", System: "Be strict."}, &ignored)
+	assert.Equal(t, "You review translations.\n\nBe strict.", req.Messages[0].Content)
+}
+
+func TestReadData(t *testing.T) {
+	prompt := "The strings are inside the <data> block.\n\n<data>\n{\n  \"b\": {\"text\": \"Bonjour\", \"source\": \"Hello\", \"maxLength\": 12},\n  \"a\": {\"text\": \"Save\"}\n}\n</data>\nFix these."
+	items, err := ReadData(prompt)
+	require.NoError(t, err)
+	assert.Equal(t, []DataItem{{Key: "b", Text: "Bonjour", Source: "Hello", MaxLength: 12}, {Key: "a", Text: "Save"}}, items, "in block order")
+
+	_, err = ReadData("no block")
 	require.Error(t, err)
 }
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
+	"path/filepath"
 )
 
 // Read parses a locale JSON file and returns its key-value map.
@@ -29,7 +29,7 @@ func Read(path string) (map[string]string, error) {
 // Write pretty-prints the translations to the given file path, creating
 // parent directories as necessary.
 func Write(path string, translations map[string]string) error {
-	dir := path[:strings.LastIndex(path, "/")]
+	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("creating directory: %w", err)
 	}
@@ -0,0 +1,75 @@
+package locales
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// Aliases maps friendly locale names, as found in older projects and on the
+// command line, to BCP 47 tags.
+var Aliases = map[string]string{
+	"pidgin":          "pcm",
+	"naija":           "pcm",
+	"nigerian-pidgin": "pcm",
+	"english":         "en",
+	"french":          "fr",
+	"german":          "de",
+	"spanish":         "es",
+	"italian":         "it",
+	"portuguese":      "pt",
+	"dutch":           "nl",
+	"russian":         "ru",
+	"ukrainian":       "uk",
+	"japanese":        "ja",
+	"chinese":         "zh",
+	"korean":          "ko",
+	"arabic":          "ar",
+	"yoruba":          "yo",
+	"igbo":            "ig",
+	"hausa":           "ha",
+	"swahili":         "sw",
+}
+
+// Parse resolves a locale name or alias to a BCP 47 tag. Underscores are
+// accepted as separators ("pt_BR") and case is normalised.
+func Parse(s string) (language.Tag, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if alias, ok := Aliases[name]; ok {
+		name = alias
+	}
+	name = strings.ReplaceAll(name, "_", "-")
+	tag, err := language.Parse(name)
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid locale %q: %w", s, err)
+	}
+	if tag == language.Und {
+		return language.Und, fmt.Errorf("invalid locale %q", s)
+	}
+	return tag, nil
+}
+
+// Canonicalize returns the canonical BCP 47 form of a locale name, e.g.
+// "pidgin" → "pcm", "pt_br" → "pt-BR", "iw" → "he".
+func Canonicalize(s string) (string, error) {
+	tag, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return tag.String(), nil
+}
+
+// DisplayName returns the English name of a locale for use in prompts, e.g.
+// "pcm" → "Nigerian Pidgin". Unknown input is returned unchanged.
+func DisplayName(s string) string {
+	tag, err := Parse(s)
+	if err != nil {
+		return s
+	}
+	if name := display.English.Tags().Name(tag); name != "" {
+		return name
+	}
+	return tag.String()
+}
@@ -0,0 +1,36 @@
+package locales
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"pidgin": "pcm",
+		"Pidgin": "pcm",
+		"pcm":    "pcm",
+		"pcm-ng": "pcm-NG",
+		"en_US":  "en-US",
+		"pt-br":  "pt-BR",
+		"iw":     "he",
+		"french": "fr",
+	} {
+		got, err := Canonicalize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, bad := range []string{"", "../etc", "not a locale", "xx"} {
+		_, err := Canonicalize(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	assert.Equal(t, "Nigerian Pidgin", DisplayName("pidgin"))
+	assert.Equal(t, "Brazilian Portuguese", DisplayName("pt-BR"))
+	assert.Equal(t, "???", DisplayName("???"))
+}
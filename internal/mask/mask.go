@@ -0,0 +1,143 @@
+// Package mask replaces markup tags and ICU arguments in a source string
+// with opaque tokens such as ⟦0⟧ before it is sent to a model, and puts
+// them back afterwards. Models cannot translate a tag name or an argument
+// they never see, and a translation whose tokens went missing, were
+// duplicated or no longer nest is rejected instead of written.
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Note explains the tokens to the model; it belongs in the system prompt
+// of any request containing masked text.
+const Note = "Tokens such as ⟦0⟧ stand for markup or placeholders. Copy every token exactly once and unchanged. You may move tokens where the grammar requires it, but keep each opening tag token before its closing tag token."
+
+type kind int
+
+const (
+	arg kind = iota
+	openTag
+	closeTag
+	selfClosingTag
+)
+
+type token struct {
+	original string
+	kind     kind
+	name     string
+}
+
+// Masker holds the tokens of one source string.
+type Masker struct {
+	// Text is the source string with every tag and argument replaced.
+	Text   string
+	tokens []token
+}
+
+var (
+	// maskRe matches a tag, or an ICU argument without nested messages
+	// ({name}, {n, number}, {d, date, short}). Plural and select arguments
+	// are left in place so their branches can be translated, but the
+	// simple arguments and tags inside them are masked.
+	maskRe  = regexp.MustCompile(`<(/?)([A-Za-z][\w.-]*)\b[^<>]*?(/?)>|\{\s*[A-Za-z_]\w*\s*(?:,\s*[a-z]+\s*(?:,[^{}]*)?)?\}`)
+	tokenRe = regexp.MustCompile(`⟦\s*(\d+)\s*⟧`)
+)
+
+// New masks source.
+func New(source string) *Masker {
+	m := &Masker{}
+	m.Text = maskRe.ReplaceAllStringFunc(source, func(s string) string {
+		t := token{original: s, kind: arg}
+		if sub := maskRe.FindStringSubmatch(s); sub[2] != "" {
+			t.name = sub[2]
+			switch {
+			case sub[3] == "/":
+				t.kind = selfClosingTag
+			case sub[1] == "/":
+				t.kind = closeTag
+			default:
+				t.kind = openTag
+			}
+		}
+		m.tokens = append(m.tokens, t)
+		return tokenString(len(m.tokens) - 1)
+	})
+	return m
+}
+
+func tokenString(id int) string { return "⟦" + strconv.Itoa(id) + "⟧" }
+
+// Strip removes the tags and ICU arguments New would mask, leaving the
+// text a reader sees apart from argument values.
+func Strip(s string) string {
+	return maskRe.ReplaceAllString(s, "")
+}
+
+// Len returns the number of tokens.
+func (m *Masker) Len() int { return len(m.tokens) }
+
+// Apply masks another rendering of the same string, such as an existing
+// translation shown for context, with the source's tokens. Tags and
+// arguments the source does not have are left alone.
+func (m *Masker) Apply(s string) string {
+	used := make([]bool, len(m.tokens))
+	return maskRe.ReplaceAllStringFunc(s, func(match string) string {
+		for id, t := range m.tokens {
+			if !used[id] && t.original == match {
+				used[id] = true
+				return tokenString(id)
+			}
+		}
+		return match
+	})
+}
+
+// Unmask restores the tags and arguments in a translation of Text. It
+// fails when a token is missing, repeated or unknown, or when tag tokens
+// no longer nest.
+func (m *Masker) Unmask(translation string) (string, error) {
+	seen := make([]int, len(m.tokens))
+	var errs []error
+	var open []int
+	for _, sub := range tokenRe.FindAllStringSubmatch(translation, -1) {
+		id, _ := strconv.Atoi(sub[1])
+		if id >= len(m.tokens) {
+			errs = append(errs, fmt.Errorf("unknown token %s", sub[0]))
+			continue
+		}
+		seen[id]++
+		switch t := m.tokens[id]; t.kind {
+		case openTag:
+			open = append(open, id)
+		case closeTag:
+			if len(open) == 0 || m.tokens[open[len(open)-1]].name != t.name {
+				errs = append(errs, fmt.Errorf("token %s closes <%s> before it is opened", tokenString(id), t.name))
+				continue
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	for id, n := range seen {
+		switch {
+		case n == 0:
+			errs = append(errs, fmt.Errorf("token %s is missing", tokenString(id)))
+		case n > 1:
+			errs = append(errs, fmt.Errorf("token %s appears %d times", tokenString(id), n))
+		}
+	}
+	if len(errs) == 0 && len(open) > 0 {
+		errs = append(errs, fmt.Errorf("token %s opens <%s> that is never closed", tokenString(open[len(open)-1]), m.tokens[open[len(open)-1]].name))
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return tokenRe.ReplaceAllStringFunc(translation, func(s string) string {
+		id, _ := strconv.Atoi(strings.Trim(s, "⟦⟧ "))
+		return m.tokens[id].original
+	}), nil
+}
@@ -0,0 +1,57 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskRoundTrip(t *testing.T) {
+	m := New(`Hi <b>{name}</b>, read the <Link href="/terms">terms</Link>`)
+	assert.Equal(t, "Hi ⟦0⟧⟦1⟧⟦2⟧, read the ⟦3⟧terms⟦4⟧", m.Text)
+	assert.Equal(t, 5, m.Len())
+
+	got, err := m.Unmask("Hallo ⟦0⟧⟦1⟧⟦2⟧, lies die ⟦3⟧AGB⟦4⟧")
+	require.NoError(t, err)
+	assert.Equal(t, `Hallo <b>{name}</b>, lies die <Link href="/terms">AGB</Link>`, got)
+}
+
+func TestMask_ICU(t *testing.T) {
+	m := New("{count, plural, one {# file by {user}} other {# files}} on {day, date, short}")
+	assert.Equal(t, "{count, plural, one {# file by ⟦0⟧} other {# files}} on ⟦1⟧", m.Text, "plural branches stay translatable")
+
+	got, err := m.Unmask("am ⟦1⟧ {count, plural, one {# Datei von ⟦0⟧} other {# Dateien}}")
+	require.NoError(t, err, "arguments may move")
+	assert.Equal(t, "am {day, date, short} {count, plural, one {# Datei von {user}} other {# Dateien}}", got)
+}
+
+func TestUnmask_Invalid(t *testing.T) {
+	m := New("<b>{name}</b> joined")
+	tests := map[string]string{
+		"⟦0⟧⟦1⟧ ist beigetreten":        "token ⟦2⟧ is missing",
+		"⟦0⟧⟦1⟧⟦2⟧ ⟦1⟧ ist beigetreten": "token ⟦1⟧ appears 2 times",
+		"⟦2⟧⟦1⟧⟦0⟧ ist beigetreten":     "token ⟦2⟧ closes <b> before it is opened",
+		"⟦0⟧⟦1⟧⟦2⟧⟦7⟧ ist beigetreten":  "unknown token ⟦7⟧",
+	}
+	for translation, want := range tests {
+		_, err := m.Unmask(translation)
+		require.Error(t, err, translation)
+		assert.Contains(t, err.Error(), want, translation)
+	}
+
+	got, err := m.Unmask("⟦ 0 ⟧⟦1⟧⟦2⟧ ist beigetreten")
+	require.NoError(t, err, "spaces inside tokens are tolerated")
+	assert.Equal(t, "<b>{name}</b> ist beigetreten", got)
+}
+
+func TestApply(t *testing.T) {
+	m := New("<b>{name}</b> joined")
+	assert.Equal(t, "⟦0⟧⟦1⟧⟦2⟧ a rejoint", m.Apply("<b>{name}</b> a rejoint"))
+	assert.Equal(t, "<i>⟦1⟧</i> a rejoint", m.Apply("<i>{name}</i> a rejoint"))
+
+	plain := New("Save")
+	assert.Equal(t, "Save", plain.Text)
+	_, err := plain.Unmask("⟦0⟧ Speichern")
+	assert.Error(t, err)
+}
@@ -17,6 +17,14 @@ type Message struct {
 		Line   int `json:"line"`
 		Column int `json:"column"`
 	} `json:"loc"`
+	// Kind is where the string appears: "text" for <Text> children or the
+	// attribute name, e.g. "title". It selects the default length budget.
+	Kind string `json:"kind,omitempty"`
+	// MaxLength caps the translation at this many characters.
+	MaxLength int `json:"maxLength,omitempty"`
+	// MaxExpansion caps the translation at this multiple of the source
+	// length, e.g. 1.3 for 30% longer.
+	MaxExpansion float64 `json:"maxExpansion,omitempty"`
 }
 
 // Read parses js/dist/messages.json (or any path with the same schema).
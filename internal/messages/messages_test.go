@@ -22,6 +22,19 @@ func TestRead_Success(t *testing.T) {
 	assert.Equal(t, msgs, got)
 }
 
+func TestRead_LengthMetadata(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "messages.json")
+	src := `[{"key": "tab", "default": "Alerts", "kind": "title", "maxLength": 12, "maxExpansion": 1.3}]`
+	require.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+
+	got, err := Read(file)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "title", got[0].Kind)
+	assert.Equal(t, 12, got[0].MaxLength)
+	assert.Equal(t, 1.3, got[0].MaxExpansion)
+}
+
 func TestRead_Malformed(t *testing.T) {
 	tmp := t.TempDir()
 	file := filepath.Join(tmp, "messages.json")
@@ -29,3 +42,44 @@ func TestRead_Malformed(t *testing.T) {
 	_, err := Read(file)
 	require.Error(t, err)
 }
+
+func TestFindCollisions(t *testing.T) {
+	a := Message{Key: "home-hello", Default: "Hello!", File: "screens/home.tsx"}
+	a.Loc.Line = 3
+	b := Message{Key: "home-hello", Default: "Hello?", File: "screens/home.tsx"}
+	b.Loc.Line = 9
+	dup := a
+
+	collisions := FindCollisions([]Message{a, b, dup})
+	require.Len(t, collisions, 1)
+	assert.Equal(t, "home-hello", collisions[0].Key)
+	assert.Equal(t, "screens/home.tsx:3:0", collisions[0].First.Position())
+	assert.Equal(t, "screens/home.tsx:9:0", collisions[0].Second.Position())
+}
+
+func TestValidate_Policies(t *testing.T) {
+	msgs := []Message{
+		{Key: "index-title", Default: "Chats", File: "chats/index.tsx"},
+		{Key: "index-title", Default: "Settings", File: "settings/index.tsx"},
+		{Key: "app-ok", Default: "OK"},
+		{Key: "app-ok", Default: "OK"},
+	}
+
+	_, collisions, err := Validate(msgs, CollisionError)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chats/index.tsx")
+	assert.Contains(t, err.Error(), "settings/index.tsx")
+	assert.Len(t, collisions, 1)
+
+	got, _, err := Validate(msgs, CollisionSkip)
+	require.NoError(t, err)
+	assert.Equal(t, []Message{{Key: "app-ok", Default: "OK"}}, got)
+
+	got, _, err = Validate(msgs, CollisionFirst)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "Chats", got[0].Default)
+
+	_, _, err = Validate(msgs, "newest")
+	require.Error(t, err)
+}
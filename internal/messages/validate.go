@@ -0,0 +1,105 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CollisionPolicy controls what Validate does with keys that map to more
+// than one default string.
+type CollisionPolicy string
+
+const (
+	// CollisionError refuses the whole manifest when any key is ambiguous.
+	CollisionError CollisionPolicy = "error"
+	// CollisionSkip drops every entry of an ambiguous key and keeps the rest.
+	CollisionSkip CollisionPolicy = "skip"
+	// CollisionFirst keeps the first occurrence of an ambiguous key.
+	CollisionFirst CollisionPolicy = "first"
+)
+
+// ParseCollisionPolicy validates a policy name coming from flags or env.
+func ParseCollisionPolicy(s string) (CollisionPolicy, error) {
+	switch p := CollisionPolicy(s); p {
+	case CollisionError, CollisionSkip, CollisionFirst:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown collision policy %q (want error, skip or first)", s)
+}
+
+// Collision describes two manifest entries that share a key but carry
+// different default text.
+type Collision struct {
+	Key    string
+	First  Message
+	Second Message
+}
+
+func (c Collision) String() string {
+	return fmt.Sprintf("key %q: %q at %s conflicts with %q at %s",
+		c.Key, c.First.Default, c.First.Position(), c.Second.Default, c.Second.Position())
+}
+
+// Position formats the source location of the message as file:line:column.
+func (m Message) Position() string {
+	return fmt.Sprintf("%s:%d:%d", m.File, m.Loc.Line, m.Loc.Column)
+}
+
+// FindCollisions reports every entry whose key was already seen with a
+// different default. Entries repeating the same key and default (the same
+// string used twice in one file) are not collisions.
+func FindCollisions(msgs []Message) []Collision {
+	first := make(map[string]Message, len(msgs))
+	var collisions []Collision
+	for _, m := range msgs {
+		prev, ok := first[m.Key]
+		if !ok {
+			first[m.Key] = m
+			continue
+		}
+		if prev.Default != m.Default {
+			collisions = append(collisions, Collision{Key: m.Key, First: prev, Second: m})
+		}
+	}
+	return collisions
+}
+
+// Validate checks the manifest for ambiguous keys and returns a manifest
+// with one entry per key, resolved according to policy. The collisions
+// found are always returned so callers can report them. An empty policy
+// behaves like CollisionError.
+func Validate(msgs []Message, policy CollisionPolicy) ([]Message, []Collision, error) {
+	if policy == "" {
+		policy = CollisionError
+	}
+	if _, err := ParseCollisionPolicy(string(policy)); err != nil {
+		return nil, nil, err
+	}
+	collisions := FindCollisions(msgs)
+	if len(collisions) > 0 && policy == CollisionError {
+		errs := make([]error, 0, len(collisions))
+		for _, c := range collisions {
+			errs = append(errs, errors.New(c.String()))
+		}
+		return nil, collisions, fmt.Errorf("manifest has %d ambiguous keys: %w", len(collisions), errors.Join(errs...))
+	}
+
+	ambiguous := make(map[string]bool, len(collisions))
+	for _, c := range collisions {
+		ambiguous[c.Key] = true
+	}
+
+	seen := make(map[string]bool, len(msgs))
+	out := make([]Message, 0, len(msgs))
+	for _, m := range msgs {
+		if seen[m.Key] {
+			continue
+		}
+		seen[m.Key] = true
+		if ambiguous[m.Key] && policy == CollisionSkip {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out, collisions, nil
+}
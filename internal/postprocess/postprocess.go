@@ -0,0 +1,373 @@
+// Package postprocess normalises translations before they are written:
+// Unicode composition, typographic quotes, spacing and punctuation rules
+// that differ between languages and that models apply inconsistently.
+package postprocess
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Rule is a named normalisation. Apply receives the base language of the
+// locale, e.g. "fr" for fr-CA, for rules whose output depends on it.
+type Rule struct {
+	Name  string
+	Doc   string
+	Apply func(s, lang string) string
+}
+
+// Rules lists every rule in the order a Pipeline applies them.
+var Rules = []Rule{
+	{"nfc", "Compose characters to Unicode NFC", func(s, _ string) string { return norm.NFC.String(s) }},
+	{"spacing", "Collapse repeated spaces between words", collapseSpaces},
+	{"ellipsis", `Replace "..." with "…"`, func(s, _ string) string { return strings.ReplaceAll(s, "...", "…") }},
+	{"quotes", `Replace straight double quotes with the language's quotes, e.g. „…“ in German`, typographicQuotes},
+	{"french-spacing", "Put a narrow no-break space before ? ! : and ;", frenchSpacing},
+	{"inverted-marks", "Open Spanish questions and exclamations with ¿ and ¡", invertedMarks},
+}
+
+// defaults are the rules applied to a language when the project does not
+// list any; every other language gets only DefaultRules.
+var defaults = map[string][]string{
+	"de": {"nfc", "ellipsis", "quotes"},
+	"es": {"nfc", "ellipsis", "quotes", "inverted-marks"},
+	"fr": {"nfc", "ellipsis", "quotes", "french-spacing"},
+}
+
+// DefaultRules are applied to languages without specific defaults.
+var DefaultRules = []string{"nfc"}
+
+// Names returns the names of all rules.
+func Names() []string {
+	names := make([]string, len(Rules))
+	for i, r := range Rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// Pipeline is the set of rules configured for one locale.
+type Pipeline struct {
+	lang  string
+	rules []Rule
+}
+
+// New returns the pipeline for locale. A nil names selects the language
+// defaults; an empty, non-nil names disables post-processing.
+func New(locale string, names []string) (Pipeline, error) {
+	lang := baseLanguage(locale)
+	if names == nil {
+		names = DefaultRules
+		if d, ok := defaults[lang]; ok {
+			names = d
+		}
+	}
+	for _, n := range names {
+		if !slices.Contains(Names(), n) {
+			return Pipeline{}, fmt.Errorf("unknown post-processing rule %q, want one of %s", n, strings.Join(Names(), ", "))
+		}
+	}
+	p := Pipeline{lang: lang}
+	for _, r := range Rules {
+		if slices.Contains(names, r.Name) {
+			p.rules = append(p.rules, r)
+		}
+	}
+	return p, nil
+}
+
+// Apply runs every rule of the pipeline over the text of the ICU message
+// s. Argument syntax, tags and mask tokens are not text: the rules see a
+// placeholder character in their place, so a sentence still reads across
+// them, and the branches of plural and select arguments are processed as
+// messages of their own.
+func (p Pipeline) Apply(s string) string {
+	if len(p.rules) == 0 {
+		return s
+	}
+	// A string that already holds placeholder characters cannot be taken
+	// apart and put back together safely.
+	if strings.ContainsFunc(s, isPlaceholder) {
+		return s
+	}
+	return p.message(s, false)
+}
+
+// The placeholders are private use characters, which the rules treat as
+// letters.
+const (
+	firstPlaceholder = '\uE000'
+	lastPlaceholder  = '\uF8FF'
+)
+
+func isPlaceholder(r rune) bool { return r >= firstPlaceholder && r <= lastPlaceholder }
+
+// keepRe matches tags and mask tokens.
+var keepRe = regexp.MustCompile(`^(?:<[^<>]*>|⟦\s*\d+\s*⟧)`)
+
+// message applies the rules to the text of an ICU message. In a plural
+// branch "#" stands for the number and is kept.
+func (p Pipeline) message(s string, inPlural bool) string {
+	var text strings.Builder
+	var kept []string
+	keep := func(piece string) {
+		text.WriteRune(firstPlaceholder + rune(len(kept)))
+		kept = append(kept, piece)
+	}
+	for i := 0; i < len(s); {
+		if loc := keepRe.FindStringIndex(s[i:]); loc != nil {
+			keep(s[i : i+loc[1]])
+			i += loc[1]
+			continue
+		}
+		switch {
+		case s[i] == '{':
+			end := closing(s, i)
+			if end < 0 {
+				// Unbalanced braces are not ICU syntax the rules could
+				// keep intact; the message is left as it is.
+				return s
+			}
+			keep(p.argument(s[i:end+1], inPlural))
+			i = end + 1
+		case s[i] == '#' && inPlural:
+			keep("#")
+			i++
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			text.WriteString(s[i : i+size])
+			i += size
+		}
+	}
+	if len(kept) > lastPlaceholder-firstPlaceholder+1 {
+		return s
+	}
+
+	out := text.String()
+	for _, r := range p.rules {
+		out = r.Apply(out, p.lang)
+	}
+	return restore(out, kept)
+}
+
+// restore puts the kept pieces back in place of their placeholders.
+func restore(s string, kept []string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if isPlaceholder(r) {
+			b.WriteString(kept[r-firstPlaceholder])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// argument applies the rules to the branches of a plural, selectordinal
+// or select argument; simple arguments such as {name} or {n, number} are
+// kept.
+func (p Pipeline) argument(arg string, inPlural bool) string {
+	parts := strings.SplitN(arg[1:len(arg)-1], ",", 3)
+	if len(parts) < 3 {
+		return arg
+	}
+	switch strings.TrimSpace(parts[1]) {
+	case "plural", "selectordinal":
+		inPlural = true
+	case "select":
+	default:
+		return arg
+	}
+	branches := parts[2]
+	var b strings.Builder
+	b.WriteString("{" + parts[0] + "," + parts[1] + ",")
+	for i := 0; i < len(branches); {
+		if branches[i] != '{' {
+			b.WriteByte(branches[i])
+			i++
+			continue
+		}
+		end := closing(branches, i)
+		if end < 0 {
+			return arg
+		}
+		b.WriteString("{" + p.message(branches[i+1:end], inPlural) + "}")
+		i = end + 1
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// closing returns the index of the brace closing the one at open, or -1.
+func closing(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// RuleNames returns the names of the rules in the pipeline.
+func (p Pipeline) RuleNames() []string {
+	names := make([]string, len(p.rules))
+	for i, r := range p.rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func baseLanguage(locale string) string {
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return strings.ToLower(locale)
+	}
+	base, _ := tag.Base()
+	return base.String()
+}
+
+// collapseSpaces replaces runs of spaces between words with one space.
+// Leading and trailing whitespace is left alone; it is often deliberate.
+func collapseSpaces(s, _ string) string {
+	start := len(s) - len(strings.TrimLeft(s, " "))
+	end := len(strings.TrimRight(s, " "))
+	if start >= end {
+		return s
+	}
+	inner := s[start:end]
+	for strings.Contains(inner, "  ") {
+		inner = strings.ReplaceAll(inner, "  ", " ")
+	}
+	return s[:start] + inner + s[end:]
+}
+
+// quotePairs are the primary opening and closing quotes per language.
+var quotePairs = map[string][2]string{
+	"de": {"„", "“"},
+	"pl": {"„", "”"},
+	"cs": {"„", "“"},
+	"nl": {"“", "”"},
+	"fr": {"«\u202F", "\u202F»"},
+	"es": {"«", "»"},
+	"it": {"«", "»"},
+	"pt": {"“", "”"},
+	"ru": {"«", "»"},
+	"uk": {"«", "»"},
+	"ja": {"「", "」"},
+	"zh": {"“", "”"},
+}
+
+// typographicQuotes pairs up straight double quotes outside of markup
+// tags. Strings with an odd number of quotes are left alone, as are
+// quotes inside tags such as <Link href="…">.
+func typographicQuotes(s, lang string) string {
+	pair, ok := quotePairs[lang]
+	if !ok {
+		pair = [2]string{"“", "”"}
+	}
+	var positions []int
+	inTag := false
+	for i, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case r == '"' && !inTag:
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 || len(positions)%2 != 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for n, i := range positions {
+		b.WriteString(s[last:i])
+		if n%2 == 0 {
+			b.WriteString(pair[0])
+		} else {
+			b.WriteString(pair[1])
+		}
+		last = i + 1
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+const narrowNBSP = '\u202F'
+
+// frenchSpacing puts a narrow no-break space before ? ! : and ; that end a
+// word, replacing any space already there. Colons inside tokens such as
+// "10:30" or "https://" are not followed by a space and are left alone.
+func frenchSpacing(s, _ string) string {
+	runes := []rune(s)
+	var out []rune
+	for i, r := range runes {
+		if !strings.ContainsRune("?!:;", r) {
+			out = append(out, r)
+			continue
+		}
+		endsToken := i+1 == len(runes) || unicode.IsSpace(runes[i+1]) || strings.ContainsRune("?!\"»)", runes[i+1])
+		trimmed := strings.TrimRightFunc(string(out), unicode.IsSpace)
+		prev, _ := lastRune(trimmed)
+		if !endsToken || trimmed == "" || strings.ContainsRune("?!:;", prev) {
+			out = append(out, r)
+			continue
+		}
+		out = append([]rune(trimmed), narrowNBSP, r)
+	}
+	return string(out)
+}
+
+// invertedMarks opens each Spanish sentence ending in ? or ! with ¿ or ¡
+// unless the sentence already contains the opening mark.
+func invertedMarks(s, _ string) string {
+	runes := []rune(s)
+	var out []rune
+	sentence := 0 // index in out where the current sentence starts
+	for i, r := range runes {
+		out = append(out, r)
+		open := map[rune]rune{'?': '¿', '!': '¡'}[r]
+		if open == 0 {
+			if r == '.' || r == '…' {
+				sentence = len(out)
+			}
+			continue
+		}
+		if i+1 < len(runes) && (runes[i+1] == '?' || runes[i+1] == '!') {
+			continue // "?!" closes the sentence on its last mark
+		}
+		start := sentence
+		for start < len(out) && unicode.IsSpace(out[start]) {
+			start++
+		}
+		if !slices.Contains(out[start:], open) && start < len(out)-1 {
+			out = slices.Insert(out, start, open)
+		}
+		sentence = len(out)
+	}
+	return string(out)
+}
+
+func lastRune(s string) (rune, bool) {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0, false
+	}
+	return runes[len(runes)-1], true
+}
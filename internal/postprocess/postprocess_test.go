@@ -0,0 +1,64 @@
+package postprocess
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func apply(t *testing.T, locale string, rules []string, s string) string {
+	t.Helper()
+	p, err := New(locale, rules)
+	require.NoError(t, err)
+	return p.Apply(s)
+}
+
+func TestFrench(t *testing.T) {
+	assert.Equal(t, "Prêt\u202F?", apply(t, "fr", nil, "Prêt ?"))
+	assert.Equal(t, "Attention\u202F: fichier supprimé\u202F!", apply(t, "fr-CA", nil, "Attention: fichier supprimé!"))
+	assert.Equal(t, "Ouvrir https://example.com à 10:30", apply(t, "fr", nil, "Ouvrir https://example.com à 10:30"))
+	assert.Equal(t, "Cliquez sur «\u202FEnvoyer\u202F»", apply(t, "fr", nil, `Cliquez sur "Envoyer"`))
+	assert.Equal(t, "Chargement…", apply(t, "fr", nil, "Chargement..."))
+
+	once := apply(t, "fr", nil, "Vraiment ?!")
+	assert.Equal(t, once, apply(t, "fr", nil, once), "rules are idempotent")
+}
+
+func TestGermanQuotes(t *testing.T) {
+	assert.Equal(t, "Tippe auf „Speichern“", apply(t, "de", nil, `Tippe auf "Speichern"`))
+	assert.Equal(t, `Siehe <Link href="/agb">AGB</Link>`, apply(t, "de", nil, `Siehe <Link href="/agb">AGB</Link>`))
+	assert.Equal(t, `Ein " allein`, apply(t, "de", nil, `Ein " allein`), "unbalanced quotes are left alone")
+}
+
+func TestSpanishInvertedMarks(t *testing.T) {
+	assert.Equal(t, "¿Quieres guardar?", apply(t, "es", nil, "Quieres guardar?"))
+	assert.Equal(t, "Listo. ¡Guardado!", apply(t, "es", nil, "Listo. Guardado!"))
+	assert.Equal(t, "¿Seguro?", apply(t, "es", nil, "¿Seguro?"))
+}
+
+func TestICUMessages(t *testing.T) {
+	assert.Equal(t,
+		"{count, plural, one {¿Borrar # archivo?} other {¿Borrar # archivos?}}",
+		apply(t, "es", nil, "{count, plural, one {Borrar # archivo?} other {Borrar # archivos?}}"))
+	assert.Equal(t, "¿Borrar {name}?", apply(t, "es", nil, "Borrar {name}?"), "a sentence reads across arguments")
+	assert.Equal(t, "¡<b>Listo</b>!", apply(t, "es", nil, "<b>Listo</b>!"))
+	assert.Equal(t,
+		"{gender, select, female {Elle dit\u202F: «\u202FOui\u202F»} other {Oui\u202F!}}",
+		apply(t, "fr", nil, `{gender, select, female {Elle dit: "Oui"} other {Oui!}}`))
+	assert.Equal(t, "{n, number, ::percent}\u202F!", apply(t, "fr", nil, "{n, number, ::percent}!"), "argument syntax is not text")
+	assert.Equal(t, "Datei ⟦0⟧ „speichern“", apply(t, "de", nil, `Datei ⟦0⟧ "speichern"`))
+	assert.Equal(t, "Oups {broken!", apply(t, "fr", nil, "Oups {broken!"), "unbalanced braces are left alone")
+}
+
+func TestNFCAndSpacing(t *testing.T) {
+	assert.Equal(t, "Café", apply(t, "pcm", nil, "Café"))
+	assert.Equal(t, " a b ", apply(t, "en", []string{"spacing"}, " a   b "))
+	assert.Equal(t, "Wait...", apply(t, "fr", []string{}, "Wait..."), "an empty list disables post-processing")
+}
+
+func TestNew_UnknownRule(t *testing.T) {
+	_, err := New("fr", []string{"nfc", "smart"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown post-processing rule "smart"`)
+}
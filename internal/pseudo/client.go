@@ -0,0 +1,54 @@
+package pseudo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/you/nogodey/internal/llm"
+)
+
+// Client is a translation provider that pseudo-localizes the data block
+// of a prompt instead of calling a model, so it needs no API key and
+// always gives the same answer.
+type Client struct {
+	// Ratio is the padding ratio, see Localize; zero means DefaultRatio.
+	Ratio float64
+}
+
+// CreateChatCompletion answers with a JSON object mapping every key of the
+// prompt's <data> block to its pseudo-localized source text.
+func (c Client) CreateChatCompletion(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if len(req.Messages) == 0 {
+		return openai.ChatCompletionResponse{}, errors.New("pseudo: empty request")
+	}
+	items, err := llm.ReadData(req.Messages[len(req.Messages)-1].Content)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("pseudo: %w", err)
+	}
+
+	ratio := c.Ratio
+	if ratio == 0 {
+		ratio = DefaultRatio
+	}
+	out := make(map[string]string, len(items))
+	for _, it := range items {
+		src := it.Text
+		if it.Source != "" {
+			src = it.Source
+		}
+		if src != "" {
+			out[it.Key] = Localize(src, ratio)
+		}
+	}
+	content, err := json.Marshal(out)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	return openai.ChatCompletionResponse{
+		Model:   req.Model,
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: string(content)}, FinishReason: openai.FinishReasonStop}},
+	}, nil
+}
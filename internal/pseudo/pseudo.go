@@ -0,0 +1,182 @@
+// Package pseudo pseudo-localizes strings for locales such as en-XA:
+// letters get accents, text is padded and wrapped in brackets. A string
+// that shows up unaccented was never localized, and one that is cut off
+// before its closing bracket does not fit its layout. Markup, ICU
+// arguments and plural or select syntax are kept intact, only the text of
+// their branches is changed.
+package pseudo
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultRatio makes pseudo-localized strings 40% longer than the source,
+// about what German or Finnish need.
+const DefaultRatio = 1.4
+
+var accents = map[rune]rune{
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+	'a': 'å', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ṁ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+}
+
+// filler pads strings with words, so padded text still wraps like text.
+var filler = []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+
+// keepRe matches text that must not change: mask tokens, tags, URLs,
+// e-mail addresses and printf verbs.
+var keepRe = regexp.MustCompile(`⟦\s*\d+\s*⟧|<[^<>]*>|(?i:\b(?:https?://|www\.)[^\s<>"']+[^\s<>"'.,;:!?)])|(?i:\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b)|%[sdvf]`)
+
+// terminals are kept after the closing bracket, where the punctuation
+// check expects them.
+const terminals = ".?!:;…。？！："
+
+// Localize pseudo-localizes s, padding it to about ratio times the length
+// of its text. A ratio of 1 or less adds no padding.
+func Localize(s string, ratio float64) string {
+	body := strings.TrimRightFunc(s, unicode.IsSpace)
+	trail := s[len(body):]
+	trimmed := strings.TrimLeftFunc(body, unicode.IsSpace)
+	lead := body[:len(body)-len(trimmed)]
+	body = trimmed
+	core := strings.TrimRight(body, terminals)
+	punct := body[len(core):]
+	if core == "" {
+		return s
+	}
+
+	var p localizer
+	out := p.message(core, false)
+	// Whole words are added until the padding is long enough.
+	n := int(math.Round(float64(p.letters) * (ratio - 1)))
+	for i := 0; n > 0; i++ {
+		w := " " + filler[i%len(filler)]
+		out += w
+		n -= len(w)
+	}
+	return lead + "[" + out + "]" + punct + trail
+}
+
+type localizer struct {
+	// letters counts the characters of text, for the padding.
+	letters int
+}
+
+// message localizes an ICU message. In a plural branch "#" stands for the
+// number and is kept.
+func (p *localizer) message(s string, inPlural bool) string {
+	var b, text strings.Builder
+	flush := func() {
+		b.WriteString(p.text(text.String()))
+		text.Reset()
+	}
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '{':
+			end := closing(s, i)
+			if end < 0 {
+				text.WriteString(s[i:])
+				i = len(s)
+				continue
+			}
+			flush()
+			b.WriteString(p.argument(s[i:end+1], inPlural))
+			i = end + 1
+		case s[i] == '#' && inPlural:
+			flush()
+			b.WriteByte('#')
+			i++
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			text.WriteString(s[i : i+size])
+			i += size
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// argument localizes the branches of a plural, selectordinal or select
+// argument; simple arguments such as {name} or {n, number} are kept.
+func (p *localizer) argument(arg string, inPlural bool) string {
+	parts := strings.SplitN(arg[1:len(arg)-1], ",", 3)
+	if len(parts) < 3 {
+		return arg
+	}
+	kind := strings.TrimSpace(parts[1])
+	switch kind {
+	case "plural", "selectordinal":
+		inPlural = true
+	case "select":
+	default:
+		return arg
+	}
+	branches := parts[2]
+	var b strings.Builder
+	b.WriteString("{" + parts[0] + "," + parts[1] + ",")
+	for i := 0; i < len(branches); {
+		if branches[i] != '{' {
+			b.WriteByte(branches[i])
+			i++
+			continue
+		}
+		end := closing(branches, i)
+		if end < 0 {
+			b.WriteString(branches[i:])
+			break
+		}
+		b.WriteString("{" + p.message(branches[i+1:end], inPlural) + "}")
+		i = end + 1
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// text accents plain text, leaving the parts keepRe matches alone.
+func (p *localizer) text(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range keepRe.FindAllStringIndex(s, -1) {
+		b.WriteString(p.accent(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(p.accent(s[last:]))
+	return b.String()
+}
+
+func (p *localizer) accent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsSpace(r) {
+			p.letters++
+		}
+		if a, ok := accents[r]; ok {
+			return a
+		}
+		return r
+	}, s)
+}
+
+// closing returns the index of the brace closing the one at open, or -1.
+func closing(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
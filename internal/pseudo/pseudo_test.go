@@ -0,0 +1,56 @@
+package pseudo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		ratio          float64
+	}{
+		{"accents and brackets", "Save", "[Šåṽé]", 1},
+		{"padding", "Save", "[Šåṽé one]", 2},
+		{"terminal punctuation stays last", "Delete?", "[Ðéļéţé]?", 1},
+		{"whitespace outside brackets", " Next ", " [Ñéẋţ] ", 1},
+		{"arguments and tags", "Hi {name}, <b>welcome</b>", "[Ĥî {name}, <b>ŵéļçöṁé</b>]", 1},
+		{"mask tokens", "Hi ⟦0⟧", "[Ĥî ⟦0⟧]", 1},
+		{"urls and emails", "See https://example.com or mail help@example.com", "[Šéé https://example.com öŕ ṁåîļ help@example.com]", 1},
+		{"plural", "{count, plural, one {# file} other {# files}}", "[{count, plural, one {# ƒîļé} other {# ƒîļéš}}]", 1},
+		{"select with nested plural", "{g, select, female {She has {n, plural, one {# cat} other {# cats}}} other {They}}", "[{g, select, female {Šĥé ĥåš {n, plural, one {# çåţ} other {# çåţš}}} other {Ţĥéý}}]", 1},
+		{"# outside plural is text", "Item #3", "[Îţéṁ #3]", 1},
+		{"empty", "  ", "  ", 1.4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Localize(tt.in, tt.ratio))
+		})
+	}
+}
+
+func TestLocalize_DefaultRatioPads(t *testing.T) {
+	got := Localize("Create account", DefaultRatio)
+	assert.Equal(t, "[Çŕéåţé åççöûñţ one two]", got)
+}
+
+func TestClient(t *testing.T) {
+	prompt := "The strings are inside the <data> block.\n\n<data>\n{\n  \"save\": {\"text\": \"Save\"},\n  \"hello\": {\"text\": \"Bonjour ⟦0⟧\", \"source\": \"Hello ⟦0⟧\"}\n}\n</data>\n"
+	resp, err := Client{Ratio: 1}.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: prompt}},
+	})
+	require.NoError(t, err)
+	var got map[string]string
+	require.NoError(t, json.Unmarshal([]byte(resp.Choices[0].Message.Content), &got))
+	assert.Equal(t, map[string]string{"save": "[Šåṽé]", "hello": "[Ĥéļļö ⟦0⟧]"}, got, "the source wins over a parent translation")
+
+	_, err = Client{}.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "no data"}},
+	})
+	require.Error(t, err)
+}
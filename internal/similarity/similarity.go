@@ -0,0 +1,79 @@
+// Package similarity scores how close two strings in the same language
+// are, for comparing a back-translation with the original source text.
+package similarity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// chrF parameters as in Popović (2015): character n-grams up to six,
+// recall weighted twice as much as precision.
+const (
+	maxOrder = 6
+	beta     = 2
+)
+
+// ChrF returns the character n-gram F-score of hypothesis against
+// reference, from 0 (nothing in common) to 1 (identical). Whitespace is
+// ignored and case is folded. Unlike word overlap, chrF gives partial
+// credit for inflections and compounds, which back-translations are full
+// of: "settings" against "setting" still scores well.
+func ChrF(reference, hypothesis string) float64 {
+	ref, hyp := normalize(reference), normalize(hypothesis)
+	if len(ref) == 0 && len(hyp) == 0 {
+		return 1
+	}
+	var precision, recall float64
+	orders := 0
+	for n := 1; n <= maxOrder; n++ {
+		refGrams, hypGrams := ngrams(ref, n), ngrams(hyp, n)
+		refTotal, hypTotal := total(refGrams), total(hypGrams)
+		if refTotal == 0 && hypTotal == 0 {
+			break
+		}
+		matches := 0
+		for g, c := range hypGrams {
+			matches += min(c, refGrams[g])
+		}
+		if hypTotal > 0 {
+			precision += float64(matches) / float64(hypTotal)
+		}
+		if refTotal > 0 {
+			recall += float64(matches) / float64(refTotal)
+		}
+		orders++
+	}
+	precision /= float64(orders)
+	recall /= float64(orders)
+	if precision == 0 && recall == 0 {
+		return 0
+	}
+	return (1 + beta*beta) * precision * recall / (beta*beta*precision + recall)
+}
+
+func normalize(s string) []rune {
+	var out []rune
+	for _, r := range strings.ToLower(s) {
+		if !unicode.IsSpace(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func ngrams(rs []rune, n int) map[string]int {
+	grams := make(map[string]int)
+	for i := 0; i+n <= len(rs); i++ {
+		grams[string(rs[i:i+n])]++
+	}
+	return grams
+}
+
+func total(grams map[string]int) int {
+	n := 0
+	for _, c := range grams {
+		n += c
+	}
+	return n
+}
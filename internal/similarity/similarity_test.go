@@ -0,0 +1,20 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChrF(t *testing.T) {
+	assert.Equal(t, 1.0, ChrF("Save changes", "save  changes"), "case and whitespace are ignored")
+	assert.Equal(t, 1.0, ChrF("", ""))
+	assert.Equal(t, 0.0, ChrF("Save", ""))
+	assert.Equal(t, 0.0, ChrF("abc", "xyz"))
+
+	near := ChrF("Delete your account", "Delete the account")
+	far := ChrF("Delete your account", "Remove the profile")
+	assert.Greater(t, near, 0.5)
+	assert.Less(t, far, 0.3)
+	assert.Greater(t, ChrF("Open settings", "Open the setting"), ChrF("Open settings", "Launch preferences"))
+}
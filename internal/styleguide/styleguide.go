@@ -0,0 +1,138 @@
+// Package styleguide loads per-locale translation style guides: tone,
+// formality, spelling conventions, forbidden words and example pairs that
+// are sent to the model with every batch for that locale.
+package styleguide
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Guide is the schema of a style guide file, e.g. js/locales/style/de.yaml.
+type Guide struct {
+	// Tone describes the voice, e.g. "friendly, concise".
+	Tone string `yaml:"tone"`
+	// Formality states the register, e.g. "informal: always use du, never Sie".
+	Formality string `yaml:"formality"`
+	// Conventions are free-form rules such as spelling or punctuation habits.
+	Conventions []string `yaml:"conventions"`
+	// Forbidden lists words and phrases that must not appear in translations.
+	Forbidden []string `yaml:"forbidden"`
+	// Examples are reference translations showing the expected style.
+	Examples []Example `yaml:"examples"`
+
+	// Path is the file the guide was loaded from.
+	Path string `yaml:"-"`
+}
+
+// Example is a source string and its approved translation.
+type Example struct {
+	Source      string `yaml:"source"`
+	Translation string `yaml:"translation"`
+}
+
+// Load reads a style guide. Unknown fields are rejected so typos do not
+// silently drop a rule.
+func Load(path string) (*Guide, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading style guide: %w", err)
+	}
+	var g Guide
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&g); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, e := range g.Examples {
+		if strings.TrimSpace(e.Source) == "" || strings.TrimSpace(e.Translation) == "" {
+			return nil, fmt.Errorf("%s: examples[%d]: source and translation are required", path, i)
+		}
+	}
+	g.Path = path
+	return &g, nil
+}
+
+// Prompt renders the guide as instructions for a translator into
+// language, ready to be appended to the system prompt. A nil or empty
+// guide renders as "".
+func (g *Guide) Prompt(language string) string {
+	if g == nil {
+		return ""
+	}
+	var b strings.Builder
+	line := func(format string, args ...any) { b.WriteString(fmt.Sprintf(format, args...) + "\n") }
+	if g.Tone != "" {
+		line("- Tone: %s", strings.TrimSpace(g.Tone))
+	}
+	if g.Formality != "" {
+		line("- Formality: %s", strings.TrimSpace(g.Formality))
+	}
+	for _, c := range g.Conventions {
+		line("- %s", strings.TrimSpace(c))
+	}
+	if len(g.Forbidden) > 0 {
+		quoted := make([]string, len(g.Forbidden))
+		for i, f := range g.Forbidden {
+			quoted[i] = fmt.Sprintf("%q", f)
+		}
+		line("- Never use: %s", strings.Join(quoted, ", "))
+	}
+	if len(g.Examples) > 0 {
+		line("\nExamples of approved translations:")
+		for _, e := range g.Examples {
+			line("%q → %q", e.Source, e.Translation)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Style guide for %s:\n%s", language, b.String())
+}
+
+// Violations returns the forbidden words that appear in text as whole
+// words, compared case-insensitively.
+func (g *Guide) Violations(text string) []string {
+	if g == nil {
+		return nil
+	}
+	lower := strings.ToLower(text)
+	var found []string
+	for _, f := range g.Forbidden {
+		if f != "" && containsWord(lower, strings.ToLower(f)) {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+// containsWord reports whether word occurs in s not directly preceded or
+// followed by a letter or digit, so "Sie" does not match "Sieben".
+func containsWord(s, word string) bool {
+	for from := 0; from < len(s); {
+		i := strings.Index(s[from:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		from = start + 1
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,64 @@
+package styleguide
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func write(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "de.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadAndPrompt(t *testing.T) {
+	path := write(t, `tone: friendly and concise
+formality: informal, always "du", never "Sie"
+conventions:
+  - Use "E-Mail", not "Email".
+forbidden: [Sie, Ihr]
+examples:
+  - source: Save your changes
+    translation: Speichere deine Änderungen
+`)
+	g, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, g.Path)
+
+	prompt := g.Prompt("German")
+	assert.Contains(t, prompt, "Style guide for German:")
+	assert.Contains(t, prompt, "- Tone: friendly and concise")
+	assert.Contains(t, prompt, `- Formality: informal, always "du", never "Sie"`)
+	assert.Contains(t, prompt, `- Use "E-Mail", not "Email".`)
+	assert.Contains(t, prompt, `- Never use: "Sie", "Ihr"`)
+	assert.Contains(t, prompt, `"Save your changes" → "Speichere deine Änderungen"`)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := Load(write(t, "tone: warm\nformalty: du\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "formalty")
+
+	_, err = Load(write(t, "examples:\n  - source: Save\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "examples[0]")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestViolations(t *testing.T) {
+	g := &Guide{Forbidden: []string{"Sie", "Kunde"}}
+	assert.Equal(t, []string{"Sie"}, g.Violations("Können sie das speichern?"))
+	assert.Empty(t, g.Violations("Speichere deine Änderungen"))
+	assert.Empty(t, g.Violations("Sieben Kunden"), "only whole words match")
+
+	var none *Guide
+	assert.Empty(t, none.Violations("Sie"))
+	assert.Empty(t, none.Prompt("German"))
+}
@@ -0,0 +1,81 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func TestAudit(t *testing.T) {
+	client := &recordingClient{reply: func(prompt string) string {
+		if !strings.Contains(prompt, `"save"`) {
+			return `{"hello": []}`
+		}
+		// "hello" is left out of the first answer and requested again.
+		return "```json\n" + `{
+  "save": [{"category": "Fluency", "severity": "minor", "note": "prefer the infinitive"}],
+  "delete": [
+    {"category": "accuracy", "severity": "critical", "note": "\"Profil\" means profile"},
+    {"category": "vibes", "severity": "major", "note": "dropped"}
+  ]
+}` + "\n```"
+	}}
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		Locales:      []string{"de"},
+		BatchSize:    10,
+		MaxRetries:   2,
+		OpenAIKey:    "test",
+		OpenAIModel:  "translator",
+		JudgeModel:   "judge",
+		LocalesDir:   dir,
+		Client:       client,
+		ManifestPath: writeManifest(t, dir, []Message{{Key: "save", Default: "Save"}, {Key: "delete", Default: "Delete account"}, {Key: "hello", Default: "Hello"}}),
+	}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), map[string]string{"save": "Speichere", "delete": "Profil löschen", "hello": "Hallo"}))
+
+	got, err := Audit(cfg, "de")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, "delete", got[0].Key, "most severe first")
+	assert.Equal(t, 10, got[0].Penalty)
+	assert.Equal(t, "critical", got[0].Severity())
+	assert.Len(t, got[0].Findings, 1, "unknown categories are dropped")
+	assert.Equal(t, `[fluency/minor] prefer the infinitive`, got[1].Findings[0].String())
+	assert.Equal(t, "hello", got[2].Key)
+	assert.Empty(t, got[2].Findings)
+	assert.Equal(t, "", got[2].Severity())
+
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], `"delete": {"text":"Profil löschen","source":"Delete account"}`)
+	assert.Contains(t, client.prompts[0], "- accuracy: ")
+	assert.Contains(t, client.systems[0], "translation reviewer")
+}
+
+func TestAudit_OrdersBySeverity(t *testing.T) {
+	minor := `{"category": "style", "severity": "minor", "note": "wordy"}`
+	client := &recordingClient{reply: func(string) string {
+		return `{"save": [` + strings.Repeat(minor+",", 10) + minor + `], "delete": [{"category": "accuracy", "severity": "critical", "note": "wrong"}]}`
+	}}
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		Locales:      []string{"de"},
+		BatchSize:    10,
+		MaxRetries:   1,
+		OpenAIKey:    "test",
+		OpenAIModel:  "translator",
+		LocalesDir:   dir,
+		Client:       client,
+		ManifestPath: writeManifest(t, dir, []Message{{Key: "save", Default: "Save"}, {Key: "delete", Default: "Delete"}}),
+	}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), map[string]string{"save": "Speichern", "delete": "Löschen"}))
+
+	got, err := Audit(cfg, "de")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "delete", got[0].Key, "a critical error outranks eleven minor ones")
+	assert.Equal(t, 11, got[1].Penalty)
+}
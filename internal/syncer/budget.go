@@ -0,0 +1,66 @@
+package syncer
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"github.com/you/nogodey/internal/mask"
+)
+
+// DefaultLengthRatios cap labels that sit in fixed-width UI, such as tab
+// titles and input placeholders. Body text wraps and has no default.
+var DefaultLengthRatios = map[string]float64{
+	"title":       1.5,
+	"label":       1.5,
+	"placeholder": 1.5,
+}
+
+// minBudgetSlack keeps ratio budgets for very short strings usable:
+// "OK" may become "Einverstanden" even though that is six times longer.
+const minBudgetSlack = 5
+
+// visibleLength counts the characters of s a reader sees, leaving out
+// tags and ICU arguments.
+func visibleLength(s string) int {
+	return utf8.RuneCountInString(mask.Strip(s))
+}
+
+// Budget returns the maximum length of a translation of m, or 0 when it
+// has none. Key budgets from the project file win over manifest metadata,
+// which wins over the ratio for the message kind. When both a length and
+// a ratio apply, the smaller budget counts.
+func (c SyncConfig) Budget(m Message) int {
+	maxLength, ratio := m.MaxLength, m.MaxExpansion
+	if b, ok := c.KeyBudgets[m.Key]; ok {
+		if b.MaxLength > 0 {
+			maxLength = b.MaxLength
+		}
+		if b.MaxExpansion > 0 {
+			ratio = b.MaxExpansion
+		}
+	}
+	if ratio == 0 {
+		ratio = c.LengthRatios[m.Kind]
+	}
+
+	budget := maxLength
+	if ratio > 0 {
+		n := visibleLength(m.Default)
+		byRatio := max(int(math.Ceil(float64(n)*ratio)), n+minBudgetSlack)
+		if budget == 0 || byRatio < budget {
+			budget = byRatio
+		}
+	}
+	return budget
+}
+
+// overBudget describes how a translation of m exceeds its budget, or
+// returns "" when it fits.
+func (c SyncConfig) overBudget(m Message, translation string) string {
+	budget := c.Budget(m)
+	if n := visibleLength(translation); budget > 0 && n > budget {
+		return fmt.Sprintf("is %d characters, the budget is %d; shorten it", n, budget)
+	}
+	return ""
+}
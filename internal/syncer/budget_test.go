@@ -0,0 +1,52 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/config"
+)
+
+func TestBudget(t *testing.T) {
+	cfg := SyncConfig{
+		LengthRatios: DefaultLengthRatios,
+		KeyBudgets:   map[string]config.Budget{"save": {MaxLength: 8}},
+	}
+	assert.Equal(t, 0, cfg.Budget(Message{Key: "intro", Default: "Welcome to the app", Kind: "text"}), "body text has no default budget")
+	assert.Equal(t, 33, cfg.Budget(Message{Key: "tab", Default: "Notifications settings", Kind: "title"}))
+	assert.Equal(t, 7, cfg.Budget(Message{Key: "ok", Default: "OK", Kind: "title"}), "short strings get some slack")
+	assert.Equal(t, 8, cfg.Budget(Message{Key: "hi", Default: "<b>Hi</b> {name}", Kind: "title", MaxLength: 10}), "the smaller budget wins")
+	assert.Equal(t, 8, cfg.Budget(Message{Key: "save", Default: "Save", MaxLength: 20}), "project keys override the manifest")
+	assert.Equal(t, 13, cfg.Budget(Message{Key: "x", Default: "Settings", MaxExpansion: 1.6}))
+
+	cfg.LengthRatios = map[string]float64{"title": 0}
+	assert.Equal(t, 0, cfg.Budget(Message{Key: "tab", Default: "Notifications settings", Kind: "title"}), "a ratio of 0 turns the budget off")
+}
+
+func TestTranslateBatch_ShortensOverBudget(t *testing.T) {
+	client := &recordingClient{reply: func(prompt string) string {
+		if strings.Contains(prompt, "shorten it") {
+			return `{"tab": "Hinweise"}`
+		}
+		return `{"tab": "Benachrichtigungen"}`
+	}}
+	batch := []Message{{Key: "tab", Default: "Alerts", Kind: "title"}}
+	cfg := SyncConfig{MaxRetries: 2, OpenAIModel: "test", LengthRatios: DefaultLengthRatios}
+
+	got, err := translateBatch(logger.New(), client, batch, localeContext{locale: "de"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"tab": "Hinweise"}, got)
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], `"tab": {"text":"Alerts","maxLength":11}`)
+	assert.Contains(t, client.prompts[0], "must fit in that many characters")
+	assert.Contains(t, client.prompts[1], "is 18 characters, the budget is 11; shorten it")
+
+	// The last attempt keeps an over-budget translation and only flags it.
+	cfg.MaxRetries = 1
+	got, err = translateBatch(logger.New(), client, batch, localeContext{locale: "de"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"tab": "Benachrichtigungen"}, got)
+}
@@ -0,0 +1,243 @@
+package syncer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/llm"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// DefaultCandidateCount is the number of candidates per high-visibility
+// key when the project does not set one.
+const DefaultCandidateCount = 3
+
+// DefaultCandidateTemperatures spread candidates from a literal to a
+// freer translation.
+var DefaultCandidateTemperatures = []float32{0.3, 0.7, 1.0}
+
+// issuePenalty is what a failed check costs a candidate, more than any
+// number of judge findings short of a critical one.
+const issuePenalty = 25
+
+// Candidate is one translation generated for a high-visibility key.
+type Candidate struct {
+	Text        string  `json:"text"`
+	Model       string  `json:"model"`
+	Temperature float32 `json:"temperature"`
+	// Penalty ranks the candidates, lowest best: issuePenalty per failed
+	// check plus the MQM points of judge findings.
+	Penalty  int       `json:"penalty"`
+	Issues   []string  `json:"issues,omitempty"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Alternates records the candidates of one key and which was chosen, so a
+// reviewer can swap in another.
+type Alternates struct {
+	Chosen     int         `json:"chosen"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// variant is how one candidate is generated.
+type variant struct {
+	model       string
+	temperature float32
+}
+
+// CandidateCount returns the number of candidates per key.
+func (c SyncConfig) CandidateCount() int {
+	if c.Candidates.Count != nil {
+		return *c.Candidates.Count
+	}
+	return DefaultCandidateCount
+}
+
+// IsCandidateKey reports whether key is translated several times.
+func (c SyncConfig) IsCandidateKey(key string) bool {
+	return slices.ContainsFunc(c.Candidates.Keys, func(p string) bool {
+		ok, _ := path.Match(p, key)
+		return ok
+	})
+}
+
+// AlternatesPath returns the file recording the candidates for locale,
+// named like the locale file inside AlternatesDir.
+func (c SyncConfig) AlternatesPath(locale string) string {
+	return c.pathIn(c.AlternatesDir, locale)
+}
+
+// variants returns the model and temperature of each candidate for locale.
+func (c SyncConfig) variants(locale string) []variant {
+	temps := c.Candidates.Temperatures
+	if len(temps) == 0 {
+		temps = DefaultCandidateTemperatures
+	}
+	models := c.Candidates.Models
+	if len(models) == 0 {
+		models = []string{c.ModelFor(locale)}
+	}
+	out := make([]variant, c.CandidateCount())
+	for i := range out {
+		out[i] = variant{model: models[i%len(models)], temperature: temps[i%len(temps)]}
+	}
+	return out
+}
+
+// splitCandidates separates the high-visibility keys of batch from the
+// rest.
+func splitCandidates(batch []Message, cfg SyncConfig) (normal, special []Message) {
+	for _, m := range batch {
+		if cfg.IsCandidateKey(m.Key) {
+			special = append(special, m)
+		} else {
+			normal = append(normal, m)
+		}
+	}
+	return normal, special
+}
+
+// generateCandidates runs the batch once per variant, scores every
+// translation with the checks and, when enabled, the judge model, and
+// returns the best translation of each key with the full list of
+// candidates. A variant that fails is logged and skipped; keys no variant
+// translated are left out.
+func generateCandidates(log *logger.Logger, client ChatClient, batch []Message, run func([]Message, localeContext) (map[string]string, error), lc localeContext, cfg SyncConfig) (map[string]string, map[string]Alternates, error) {
+	suite, err := cfg.Checks()
+	if err != nil {
+		return nil, nil, err
+	}
+	detector := cfg.Detector(lc.locale)
+	candidates := make(map[string][]Candidate, len(batch))
+	var lastErr error
+	for i, v := range cfg.variants(lc.locale) {
+		vlc := lc
+		vlc.model, vlc.temperature = v.model, &v.temperature
+		vlc.summary = nil // only the chosen candidates count
+		log.Info("generating candidates", "locale", lc.locale, "candidate", i+1, "model", v.model, "temperature", v.temperature, "keys", len(batch))
+		translations, err := run(batch, vlc)
+		if err != nil {
+			lastErr = err
+			log.Warn("candidate generation failed", "locale", lc.locale, "candidate", i+1, "error", err.Error())
+			continue
+		}
+		var findings map[string][]Finding
+		if cfg.Candidates.Judge && len(translations) > 0 {
+			findings = judgeCandidates(log, client, batch, translations, lc, cfg)
+		}
+		for _, m := range batch {
+			t, ok := translations[m.Key]
+			if !ok {
+				continue
+			}
+			c := Candidate{Text: t, Model: v.model, Temperature: v.temperature, Findings: findings[m.Key]}
+			for _, issue := range suite.Run(m.Key, m.Default, t) {
+				c.Issues = append(c.Issues, "["+issue.Check+"] "+issue.Msg)
+			}
+			for _, msg := range detector.Check(m.Key, m.Default, t) {
+				c.Issues = append(c.Issues, "["+LanguageCheck+"] "+msg)
+			}
+			if over := cfg.overBudget(m, t); over != "" {
+				c.Issues = append(c.Issues, "[length] "+over)
+			}
+			c.Penalty = issuePenalty * len(c.Issues)
+			for _, f := range c.Findings {
+				c.Penalty += severityPenalties[f.Severity]
+			}
+			candidates[m.Key] = append(candidates[m.Key], c)
+		}
+	}
+	if len(candidates) == 0 && lastErr != nil {
+		return nil, nil, lastErr
+	}
+
+	chosen := make(map[string]string, len(candidates))
+	alternates := make(map[string]Alternates, len(candidates))
+	for key, cs := range candidates {
+		best := 0
+		for i, c := range cs {
+			if c.Penalty < cs[best].Penalty {
+				best = i
+			}
+		}
+		chosen[key] = cs[best].Text
+		lc.summary.record(map[string]string{key: cs[best].Text}, ParseModelRef(cs[best].Model, cfg.provider()), false)
+		alternates[key] = Alternates{Chosen: best, Candidates: cs}
+	}
+	return chosen, alternates, nil
+}
+
+// judgeCandidates has the judge review one variant's translations. A
+// failed review only loses the judge's opinion.
+func judgeCandidates(log *logger.Logger, client ChatClient, batch []Message, translations map[string]string, lc localeContext, cfg SyncConfig) map[string][]Finding {
+	var judged []Message
+	for _, m := range batch {
+		if _, ok := translations[m.Key]; ok {
+			judged = append(judged, m)
+		}
+	}
+	req := llm.Request{
+		Model:      cfg.Judge(),
+		BasePrompt: judgePrompt,
+		System:     lc.guide.Prompt(locales.DisplayName(lc.locale)),
+	}
+	findings, err := judgeWithRetries(log, client, judged, translations, lc.locale, req, cfg)
+	if err != nil {
+		log.Warn("judging candidates failed, ranking by checks only", "locale", lc.locale, "error", err.Error())
+	}
+	return findings
+}
+
+// writeAlternates merges the candidates of this sync into the alternates
+// file of locale; keys from earlier syncs are kept. An empty AlternatesDir
+// disables the file.
+func writeAlternates(cfg SyncConfig, locale string, alternates map[string]Alternates) error {
+	if cfg.AlternatesDir == "" || len(alternates) == 0 {
+		return nil
+	}
+	path := cfg.AlternatesPath(locale)
+	all, err := ReadAlternates(path)
+	if err != nil {
+		return err
+	}
+	for k, a := range alternates {
+		all[k] = a
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling alternates: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing alternates %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadAlternates reads an alternates file; a missing file has none.
+func ReadAlternates(path string) (map[string]Alternates, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]Alternates), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading alternates: %w", err)
+	}
+	var all map[string]Alternates
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parsing alternates %s: %w", path, err)
+	}
+	if all == nil {
+		all = make(map[string]Alternates)
+	}
+	return all, nil
+}
@@ -0,0 +1,84 @@
+package syncer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// variantClient answers by model and temperature, and reviews as a judge
+// when asked to.
+type variantClient struct {
+	models []string
+	temps  []float32
+}
+
+func (v *variantClient) CreateChatCompletion(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	v.models = append(v.models, req.Model)
+	v.temps = append(v.temps, req.Temperature)
+	prompt := req.Messages[len(req.Messages)-1].Content
+	reply := `{"title": "Starten Sie jetzt Ihre kostenlose Testphase", "body": "Text"}`
+	switch {
+	case req.Model == "judge":
+		reply = `{"title": [{"category": "style", "severity": "minor", "note": "formal"}]}`
+	case !strings.Contains(prompt, `"title"`):
+		reply = `{"body": "Text"}`
+	case req.Temperature > 0.5:
+		reply = `{"title": "Jetzt gratis testen"}`
+	}
+	return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: reply}}}}, nil
+}
+
+func TestSyncLocale_KeepsBestCandidate(t *testing.T) {
+	dir := t.TempDir()
+	count := 2
+	client := &variantClient{}
+	cfg := SyncConfig{
+		SourceLocale:  "en",
+		BatchSize:     10,
+		MaxRetries:    1,
+		OpenAIModel:   "translator",
+		JudgeModel:    "judge",
+		LocalesDir:    dir,
+		AlternatesDir: filepath.Join(dir, "alternates"),
+		KeyBudgets:    map[string]config.Budget{"title": {MaxLength: 25}},
+		Candidates: config.Candidates{
+			Keys:         []string{"paywall-*", "title"},
+			Count:        &count,
+			Temperatures: []float32{0.2, 0.9},
+			Judge:        true,
+		},
+		Client: client,
+	}
+	msgs := []Message{{Key: "title", Default: "Start your free trial now"}, {Key: "body", Default: "Text"}}
+
+	_, err := syncLocale(logger.New(), msgs, "de", cfg)
+	require.NoError(t, err)
+	got, err := locales.Read(cfg.LocalePath("de"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"title": "Jetzt gratis testen", "body": "Text"}, got)
+
+	alts, err := ReadAlternates(cfg.AlternatesPath("de"))
+	require.NoError(t, err)
+	require.Contains(t, alts, "title")
+	assert.NotContains(t, alts, "body", "only high-visibility keys get candidates")
+	a := alts["title"]
+	assert.Equal(t, 1, a.Chosen)
+	require.Len(t, a.Candidates, 2)
+	assert.Equal(t, "Starten Sie jetzt Ihre kostenlose Testphase", a.Candidates[0].Text)
+	assert.Equal(t, float32(0.2), a.Candidates[0].Temperature)
+	assert.Equal(t, issuePenalty+1, a.Candidates[0].Penalty, "over budget plus a minor finding")
+	assert.Contains(t, a.Candidates[0].Issues[0], "[length]")
+	assert.Equal(t, 1, a.Candidates[1].Penalty)
+	assert.Equal(t, "translator", a.Candidates[1].Model)
+
+	assert.Equal(t, []string{"translator", "translator", "judge", "translator", "judge"}, client.models)
+}
@@ -0,0 +1,59 @@
+package syncer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/llm"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// TestSyncCommand_ReplaysCassette records a sync against a stub and
+// replays it offline, without an API key, to the same locale file.
+func TestSyncCommand_ReplaysCassette(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	require.NoError(t, os.Chdir(tmp))
+
+	require.NoError(t, os.MkdirAll(filepath.Join("js", "dist"), 0o755))
+	data, _ := json.Marshal([]Message{{Key: "save", Default: "Save"}, {Key: "cancel", Default: "Cancel"}})
+	require.NoError(t, os.WriteFile(DefaultManifestPath, data, 0o644))
+
+	rec, err := llm.LoadCassette(DefaultCassettePath, llm.ModeRecord)
+	require.NoError(t, err)
+	stub := stubClient{resp: openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"save": "Speichern", "cancel": "Abbrechen"}`}}}}}
+	t.Setenv("SYNC_DEFAULT_LOCALES", "de")
+	t.Setenv("OPENAI_API_KEY", "test")
+	cfg, err := ResolveConfig(nil, Flags{})
+	require.NoError(t, err)
+	cfg.Client = rec.Client(stub)
+	require.NoError(t, SyncCommand(cfg))
+	recorded, err := locales.Read(cfg.LocalePath("de"))
+	require.NoError(t, err)
+	require.Equal(t, 1, rec.Len())
+
+	require.NoError(t, os.RemoveAll(DefaultLocalesDir))
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("NOGODEY_LLM_MODE", "replay")
+	cfg, err = ResolveConfig(nil, Flags{})
+	require.NoError(t, err)
+	require.NoError(t, SyncCommand(cfg))
+	replayed, err := locales.Read(cfg.LocalePath("de"))
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+	assert.Equal(t, map[string]string{"save": "Speichern", "cancel": "Abbrechen"}, replayed)
+}
+
+func TestResolveConfig_ReplayNeedsCassette(t *testing.T) {
+	t.Setenv("NOGODEY_LLM_MODE", "replay")
+	t.Setenv("NOGODEY_LLM_CASSETTE", filepath.Join(t.TempDir(), "none.json"))
+	_, err := ResolveConfig(nil, Flags{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NOGODEY_LLM_MODE: cassette")
+}
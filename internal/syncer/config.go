@@ -0,0 +1,708 @@
+package syncer
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/you/nogodey/internal/checks"
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/langdetect"
+	"github.com/you/nogodey/internal/llm"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/messages"
+	"github.com/you/nogodey/internal/postprocess"
+	"github.com/you/nogodey/internal/pseudo"
+)
+
+// Default project paths, relative to the working directory.
+const (
+	DefaultManifestPath  = "js/dist/messages.json"
+	DefaultLocalesDir    = "js/locales"
+	DefaultLocalePattern = "{locale}.json"
+	DefaultMergedDir     = "js/dist/locales"
+	DefaultStyleGuideDir = "js/locales/style"
+	DefaultAlternatesDir = "js/locales/alternates"
+	DefaultModelsDir     = "js/locales/models"
+	DefaultCassettePath  = "testdata/llm-cassette.json"
+)
+
+// Built-in defaults, the lowest configuration layer.
+const (
+	DefaultLocale     = "pcm"
+	DefaultSource     = "en"
+	DefaultProvider   = "openai"
+	DefaultModel      = "gpt-3.5-turbo"
+	DefaultBatchSize  = 200
+	DefaultMaxRetries = 3
+)
+
+// SyncConfig holds configuration for the sync command.
+type SyncConfig struct {
+	Locales []string
+	// SourceLocale is the language Message.Default is written in.
+	SourceLocale string
+	// PivotLocale, when set, is an already translated locale whose
+	// translations are used as the input for other locales.
+	PivotLocale string
+	BatchSize   int
+	MaxRetries  int
+	// MaxTokens and MaxCost, in USD, stop a sync from starting new
+	// batches once the run has used that much. Zero means no limit.
+	MaxTokens   int
+	MaxCost     float64
+	OpenAIKey   string
+	OpenAIModel string
+	// JudgeModel reviews translations in Audit; empty means OpenAIModel.
+	JudgeModel string
+	// Provider names the translation backend, see config.Providers.
+	Provider string
+	// ManifestPath is the messages.json produced by the JS build.
+	ManifestPath string
+	// LocalesDir is the directory holding the translation files.
+	LocalesDir string
+	// LocalePattern is the file name of a locale inside LocalesDir; every
+	// "{locale}" is replaced by the locale code, e.g. "{locale}/common.json".
+	LocalePattern string
+	// MergedDir receives runtime-ready locale files that fall back through
+	// parent locales to the source text. ResolveConfig always sets it, to
+	// DefaultMergedDir unless configured; only a SyncConfig built in code
+	// can leave it empty, which disables the files.
+	MergedDir string
+	// StyleGuideDir holds optional per-locale style guides, {locale}.yaml.
+	StyleGuideDir string
+	// AlternatesDir receives the candidates not chosen for high-visibility
+	// strings, see Candidates.
+	AlternatesDir string
+	// ModelsDir receives which model produced each translation.
+	ModelsDir string
+	// Fallbacks are models, "provider/model" or a bare model name, tried
+	// in order when the locale's model keeps failing.
+	Fallbacks []string
+	// CollisionPolicy decides how ambiguous manifest keys are handled.
+	CollisionPolicy messages.CollisionPolicy
+	Glossary        []config.GlossaryEntry
+	LocaleOptions   map[string]config.LocaleOptions
+	// MaxReferences is how many other locales' translations of a key are
+	// quoted in the prompt for disambiguation; 0 disables references.
+	MaxReferences int
+	// ReferenceLocales restricts and orders the reference candidates.
+	ReferenceLocales []string
+	// DisabledChecks names translation checks that are skipped.
+	DisabledChecks []string
+	// AllowIdentical lists keys or source strings that may be translated
+	// as the source text.
+	AllowIdentical []string
+	// LengthRatios caps translations at a multiple of the source length
+	// per message kind; see Budget.
+	LengthRatios map[string]float64
+	// Candidates selects the keys translated several times, keeping the
+	// best translation.
+	Candidates config.Candidates
+	// KeyBudgets overrides the length budget of single keys and sets
+	// their pixel widths.
+	KeyBudgets map[string]config.Budget
+	// Fonts are the font files used to measure pixel widths.
+	Fonts []string
+	// FontSize is the text size, in pixels per em, of keys whose budget
+	// does not set one.
+	FontSize float64
+	// ExecCommand is the translator command of the exec provider.
+	ExecCommand []string
+	// PseudoRatio pads the strings of the pseudo provider to this multiple
+	// of the source length.
+	PseudoRatio float64
+	// Pricing maps a model to its price, see DefaultPrices.
+	Pricing map[string]config.Price
+	// Sources records where each setting came from, keyed by setting name.
+	Sources map[string]string
+	Client  llm.ChatClient // allows tests to inject a stub
+	// LLMMode is "record" or "replay" to run LLM calls through the
+	// cassette at CassettePath, see llm.Cassette; empty calls the
+	// provider directly.
+	LLMMode      string
+	CassettePath string
+
+	// cassette is loaded by ResolveConfig when LLMMode is set.
+	cassette *llm.Cassette
+	// spent is the token usage of the whole run, checked against
+	// MaxTokens and MaxCost.
+	spent usage
+}
+
+// Flags carries the sync options given on the command line. Only flags the
+// user actually passed should be set; zero values fall through to the
+// environment, the project file and the defaults, in that order.
+type Flags struct {
+	Locales       []string
+	SourceLocale  string
+	PivotLocale   string
+	BatchSize     *int
+	MaxRetries    *int
+	MaxReferences *int
+	MaxTokens     *int
+	MaxCost       *float64
+	Provider      string
+	Model         string
+	JudgeModel    string
+	Fallbacks     []string
+	ManifestPath  string
+	LocalesDir    string
+	LocalePattern string
+	MergedDir     string
+	StyleGuideDir string
+	OnCollision   string
+}
+
+// loadEnvConfig loads environment variables from a .env file if present.
+func loadEnvConfig() { _ = godotenv.Load() }
+
+// ResolveConfig layers flags over environment variables over the project
+// file over built-in defaults. project may be nil when there is no file.
+func ResolveConfig(project *config.Project, flags Flags) (SyncConfig, error) {
+	loadEnvConfig()
+
+	cfg := SyncConfig{
+		Locales:         []string{DefaultLocale},
+		SourceLocale:    DefaultSource,
+		BatchSize:       DefaultBatchSize,
+		MaxRetries:      DefaultMaxRetries,
+		OpenAIModel:     DefaultModel,
+		Provider:        DefaultProvider,
+		ManifestPath:    DefaultManifestPath,
+		LocalesDir:      DefaultLocalesDir,
+		LocalePattern:   DefaultLocalePattern,
+		MergedDir:       DefaultMergedDir,
+		StyleGuideDir:   DefaultStyleGuideDir,
+		AlternatesDir:   DefaultAlternatesDir,
+		ModelsDir:       DefaultModelsDir,
+		CollisionPolicy: messages.CollisionError,
+		LengthRatios:    maps.Clone(DefaultLengthRatios),
+		FontSize:        DefaultFontSize,
+		Pricing:         maps.Clone(DefaultPrices),
+		PseudoRatio:     pseudo.DefaultRatio,
+		Sources:         make(map[string]string),
+	}
+	for _, name := range []string{"locales", "source_locale", "pivot_locale", "batch_size", "max_retries", "model", "provider", "manifest", "locales_dir", "locale_pattern", "merged_dir", "style_guide_dir", "on_collision", "max_references"} {
+		cfg.Sources[name] = "default"
+	}
+
+	if project != nil {
+		src := "file " + project.Path
+		cfg.setList(&cfg.Locales, "locales", project.Locales, src)
+		cfg.setString(&cfg.SourceLocale, "source_locale", project.SourceLocale, src)
+		cfg.setString(&cfg.PivotLocale, "pivot_locale", project.PivotLocale, src)
+		cfg.setInt(&cfg.BatchSize, "batch_size", project.Batching.Size, src)
+		cfg.setInt(&cfg.MaxRetries, "max_retries", project.Batching.MaxRetries, src)
+		cfg.setInt(&cfg.MaxTokens, "max_tokens", project.Batching.MaxTokens, src)
+		cfg.setFloat(&cfg.MaxCost, "max_cost", project.Batching.MaxCost, src)
+		maps.Copy(cfg.Pricing, project.Pricing)
+		cfg.setList(&cfg.ExecCommand, "exec_command", project.Exec.Command, src)
+		if project.Pseudo.Ratio != 0 {
+			cfg.PseudoRatio = project.Pseudo.Ratio
+			cfg.Sources["pseudo_ratio"] = src
+		}
+		if len(project.Pricing) > 0 {
+			cfg.Sources["pricing"] = src
+		}
+		cfg.setString(&cfg.OpenAIModel, "model", project.Model, src)
+		cfg.setString(&cfg.Provider, "provider", project.Provider, src)
+		cfg.setString(&cfg.JudgeModel, "judge_model", project.Judge.Model, src)
+		cfg.setString(&cfg.ManifestPath, "manifest", project.Paths.Manifest, src)
+		cfg.setString(&cfg.LocalesDir, "locales_dir", project.Paths.LocalesDir, src)
+		cfg.setString(&cfg.LocalePattern, "locale_pattern", project.Paths.LocalePattern, src)
+		cfg.setString(&cfg.MergedDir, "merged_dir", project.Paths.MergedDir, src)
+		cfg.setString(&cfg.StyleGuideDir, "style_guide_dir", project.Paths.StyleGuideDir, src)
+		cfg.setString(&cfg.AlternatesDir, "alternates_dir", project.Paths.AlternatesDir, src)
+		cfg.setString(&cfg.ModelsDir, "models_dir", project.Paths.ModelsDir, src)
+		cfg.setList(&cfg.Fallbacks, "fallbacks", project.Fallbacks, src)
+		cfg.Candidates = project.Candidates
+		cfg.Sources["candidates"] = src
+		cfg.setString((*string)(&cfg.CollisionPolicy), "on_collision", project.OnCollision, src)
+		cfg.setInt(&cfg.MaxReferences, "max_references", project.References.Max, src)
+		cfg.setList(&cfg.ReferenceLocales, "reference_locales", project.References.Locales, src)
+		cfg.Glossary = project.Glossary
+		cfg.setList(&cfg.DisabledChecks, "disabled_checks", project.Checks.Disable, src)
+		cfg.setList(&cfg.AllowIdentical, "allow_identical", project.Checks.AllowIdentical, src)
+		maps.Copy(cfg.LengthRatios, project.Length.Ratios)
+		cfg.KeyBudgets = project.Length.Keys
+		cfg.setList(&cfg.Fonts, "fonts", project.Length.Fonts, src)
+		if project.Length.FontSize > 0 {
+			cfg.FontSize = project.Length.FontSize
+			cfg.Sources["font_size"] = src
+		}
+		cfg.Sources["length"] = src
+		cfg.LocaleOptions = project.LocaleOptions
+		cfg.Sources["glossary"] = src
+		cfg.Sources["locale_options"] = src
+	}
+
+	cfg.OpenAIKey = os.Getenv("OPENAI_API_KEY")
+	cfg.setList(&cfg.Locales, "locales", splitList(os.Getenv("SYNC_DEFAULT_LOCALES")), "env SYNC_DEFAULT_LOCALES")
+	cfg.setString(&cfg.SourceLocale, "source_locale", os.Getenv("SYNC_SOURCE_LOCALE"), "env SYNC_SOURCE_LOCALE")
+	cfg.setString(&cfg.PivotLocale, "pivot_locale", os.Getenv("SYNC_PIVOT_LOCALE"), "env SYNC_PIVOT_LOCALE")
+	cfg.setString(&cfg.OpenAIModel, "model", os.Getenv("OPENAI_MODEL"), "env OPENAI_MODEL")
+	cfg.setString(&cfg.Provider, "provider", os.Getenv("SYNC_PROVIDER"), "env SYNC_PROVIDER")
+	cfg.setString(&cfg.JudgeModel, "judge_model", os.Getenv("SYNC_JUDGE_MODEL"), "env SYNC_JUDGE_MODEL")
+	cfg.setList(&cfg.Fallbacks, "fallbacks", splitList(os.Getenv("SYNC_FALLBACK_MODELS")), "env SYNC_FALLBACK_MODELS")
+	cfg.setString(&cfg.ManifestPath, "manifest", os.Getenv("SYNC_MANIFEST"), "env SYNC_MANIFEST")
+	cfg.setString(&cfg.LocalesDir, "locales_dir", os.Getenv("SYNC_LOCALES_DIR"), "env SYNC_LOCALES_DIR")
+	cfg.setString(&cfg.LocalePattern, "locale_pattern", os.Getenv("SYNC_LOCALE_PATTERN"), "env SYNC_LOCALE_PATTERN")
+	cfg.setString(&cfg.MergedDir, "merged_dir", os.Getenv("SYNC_MERGED_DIR"), "env SYNC_MERGED_DIR")
+	cfg.setString(&cfg.StyleGuideDir, "style_guide_dir", os.Getenv("SYNC_STYLE_GUIDE_DIR"), "env SYNC_STYLE_GUIDE_DIR")
+	cfg.setString((*string)(&cfg.CollisionPolicy), "on_collision", os.Getenv("SYNC_ON_COLLISION"), "env SYNC_ON_COLLISION")
+	cfg.setString(&cfg.LLMMode, "llm_mode", os.Getenv("NOGODEY_LLM_MODE"), "env NOGODEY_LLM_MODE")
+	cfg.setString(&cfg.CassettePath, "cassette", os.Getenv("NOGODEY_LLM_CASSETTE"), "env NOGODEY_LLM_CASSETTE")
+	batchSize, err := envInt("SYNC_BATCH_SIZE")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.setInt(&cfg.BatchSize, "batch_size", batchSize, "env SYNC_BATCH_SIZE")
+	maxRetries, err := envInt("SYNC_MAX_RETRIES")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.setInt(&cfg.MaxRetries, "max_retries", maxRetries, "env SYNC_MAX_RETRIES")
+	maxReferences, err := envInt("SYNC_MAX_REFERENCES")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.setInt(&cfg.MaxReferences, "max_references", maxReferences, "env SYNC_MAX_REFERENCES")
+	maxTokens, err := envInt("SYNC_MAX_TOKENS")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.setInt(&cfg.MaxTokens, "max_tokens", maxTokens, "env SYNC_MAX_TOKENS")
+	maxCost, err := envFloat("SYNC_MAX_COST")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.setFloat(&cfg.MaxCost, "max_cost", maxCost, "env SYNC_MAX_COST")
+	cfg.setList(&cfg.ReferenceLocales, "reference_locales", splitList(os.Getenv("SYNC_REFERENCE_LOCALES")), "env SYNC_REFERENCE_LOCALES")
+
+	cfg.setList(&cfg.Locales, "locales", flags.Locales, "flag --locales")
+	cfg.setString(&cfg.SourceLocale, "source_locale", flags.SourceLocale, "flag --source-locale")
+	cfg.setString(&cfg.PivotLocale, "pivot_locale", flags.PivotLocale, "flag --pivot-locale")
+	cfg.setInt(&cfg.BatchSize, "batch_size", flags.BatchSize, "flag --batch-size")
+	cfg.setInt(&cfg.MaxRetries, "max_retries", flags.MaxRetries, "flag --max-retries")
+	cfg.setInt(&cfg.MaxReferences, "max_references", flags.MaxReferences, "flag --max-references")
+	cfg.setInt(&cfg.MaxTokens, "max_tokens", flags.MaxTokens, "flag --max-tokens")
+	cfg.setFloat(&cfg.MaxCost, "max_cost", flags.MaxCost, "flag --max-cost")
+	cfg.setString(&cfg.OpenAIModel, "model", flags.Model, "flag --model")
+	cfg.setString(&cfg.Provider, "provider", flags.Provider, "flag --provider")
+	cfg.setString(&cfg.JudgeModel, "judge_model", flags.JudgeModel, "flag --judge-model")
+	cfg.setList(&cfg.Fallbacks, "fallbacks", flags.Fallbacks, "flag --fallback-models")
+	cfg.setString(&cfg.ManifestPath, "manifest", flags.ManifestPath, "flag --manifest")
+	cfg.setString(&cfg.LocalesDir, "locales_dir", flags.LocalesDir, "flag --locales-dir")
+	cfg.setString(&cfg.LocalePattern, "locale_pattern", flags.LocalePattern, "flag --locale-pattern")
+	cfg.setString(&cfg.MergedDir, "merged_dir", flags.MergedDir, "flag --merged-dir")
+	cfg.setString(&cfg.StyleGuideDir, "style_guide_dir", flags.StyleGuideDir, "flag --style-guide-dir")
+	cfg.setString((*string)(&cfg.CollisionPolicy), "on_collision", flags.OnCollision, "flag --on-collision")
+
+	if _, err := messages.ParseCollisionPolicy(string(cfg.CollisionPolicy)); err != nil {
+		return cfg, fmt.Errorf("%s: %w", cfg.Sources["on_collision"], err)
+	}
+	if cfg.LLMMode != "" {
+		if cfg.CassettePath == "" {
+			cfg.CassettePath = DefaultCassettePath
+		}
+		if cfg.cassette, err = llm.LoadCassette(cfg.CassettePath, cfg.LLMMode); err != nil {
+			return cfg, fmt.Errorf("NOGODEY_LLM_MODE: %w", err)
+		}
+	}
+	cfg.canonicalize()
+	return cfg, nil
+}
+
+// canonicalize rewrites locale names to BCP 47 so "pidgin", "pcm" and
+// "PCM" all refer to the same files and options. Names that do not parse
+// are left alone for Validate to report.
+func (c *SyncConfig) canonicalize() {
+	canon := func(l string) string {
+		if tag, err := locales.Canonicalize(l); err == nil {
+			return tag
+		}
+		return l
+	}
+
+	list := make([]string, len(c.Locales))
+	for i, l := range c.Locales {
+		list[i] = canon(l)
+	}
+	c.Locales = list
+	if c.SourceLocale != "" {
+		c.SourceLocale = canon(c.SourceLocale)
+	}
+	if c.PivotLocale != "" {
+		c.PivotLocale = canon(c.PivotLocale)
+	}
+	if c.ReferenceLocales != nil {
+		refs := make([]string, len(c.ReferenceLocales))
+		for i, l := range c.ReferenceLocales {
+			refs[i] = canon(l)
+		}
+		c.ReferenceLocales = refs
+	}
+
+	if c.LocaleOptions != nil {
+		opts := make(map[string]config.LocaleOptions, len(c.LocaleOptions))
+		for l, o := range c.LocaleOptions {
+			if o.Parent != "" {
+				o.Parent = canon(o.Parent)
+			}
+			if o.Pivot != "" {
+				o.Pivot = canon(o.Pivot)
+			}
+			opts[canon(l)] = o
+		}
+		c.LocaleOptions = opts
+	}
+
+	glossary := make([]config.GlossaryEntry, len(c.Glossary))
+	for i, g := range c.Glossary {
+		glossary[i] = g
+		if g.Translations == nil {
+			continue
+		}
+		glossary[i].Translations = make(map[string]string, len(g.Translations))
+		for l, t := range g.Translations {
+			glossary[i].Translations[canon(l)] = t
+		}
+	}
+	c.Glossary = glossary
+}
+
+func (c *SyncConfig) setString(dst *string, name, v, source string) {
+	if v == "" {
+		return
+	}
+	*dst = v
+	c.Sources[name] = source
+}
+
+func (c *SyncConfig) setInt(dst *int, name string, v *int, source string) {
+	if v == nil {
+		return
+	}
+	*dst = *v
+	c.Sources[name] = source
+}
+
+func (c *SyncConfig) setFloat(dst *float64, name string, v *float64, source string) {
+	if v == nil {
+		return
+	}
+	*dst = *v
+	c.Sources[name] = source
+}
+
+func (c *SyncConfig) setList(dst *[]string, name string, v []string, source string) {
+	if len(v) == 0 {
+		return
+	}
+	*dst = v
+	c.Sources[name] = source
+}
+
+// envInt reads an integer environment variable; nil means unset.
+func envInt(key string) (*int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %q is not an integer", key, v)
+	}
+	return &n, nil
+}
+
+// envFloat reads a number environment variable; nil means unset.
+func envFloat(key string) (*float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %q is not a number", key, v)
+	}
+	return &f, nil
+}
+
+// splitList parses a comma-separated list, dropping surrounding spaces.
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+// Setting is one resolved configuration value and where it came from.
+type Setting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// Settings lists the resolved configuration for `nogodey config print`.
+// The API key is never printed, only whether it is set.
+func (c SyncConfig) Settings() []Setting {
+	key := "(not set)"
+	keySource := "env OPENAI_API_KEY"
+	if c.OpenAIKey != "" {
+		key = "(set)"
+	}
+	settings := []Setting{
+		{"locales", strings.Join(c.Locales, ","), c.Sources["locales"]},
+		{"source_locale", c.Source(), c.Sources["source_locale"]},
+		{"pivot_locale", c.PivotLocale, c.Sources["pivot_locale"]},
+		{"provider", c.Provider, c.Sources["provider"]},
+		{"model", c.OpenAIModel, c.Sources["model"]},
+		{"api_key", key, keySource},
+		{"batch_size", strconv.Itoa(c.BatchSize), c.Sources["batch_size"]},
+		{"max_retries", strconv.Itoa(c.MaxRetries), c.Sources["max_retries"]},
+		{"manifest", c.ManifestPath, c.Sources["manifest"]},
+		{"locales_dir", c.LocalesDir, c.Sources["locales_dir"]},
+		{"locale_pattern", c.LocalePattern, c.Sources["locale_pattern"]},
+		{"merged_dir", c.MergedDir, c.Sources["merged_dir"]},
+		{"style_guide_dir", c.StyleGuideDir, c.Sources["style_guide_dir"]},
+		{"on_collision", string(c.CollisionPolicy), c.Sources["on_collision"]},
+		{"max_references", strconv.Itoa(c.MaxReferences), c.Sources["max_references"]},
+	}
+	if len(c.Candidates.Keys) > 0 {
+		source := c.Sources["alternates_dir"]
+		if source == "" {
+			source = "default"
+		}
+		settings = append(settings,
+			Setting{"candidates.keys", strings.Join(c.Candidates.Keys, ","), c.Sources["candidates"]},
+			Setting{"candidates.count", strconv.Itoa(c.CandidateCount()), c.Sources["candidates"]},
+			Setting{"alternates_dir", c.AlternatesDir, source})
+	}
+	if len(c.Fallbacks) > 0 {
+		source := c.Sources["models_dir"]
+		if source == "" {
+			source = "default"
+		}
+		settings = append(settings,
+			Setting{"fallbacks", strings.Join(c.Fallbacks, ","), c.Sources["fallbacks"]},
+			Setting{"models_dir", c.ModelsDir, source})
+	}
+	if len(c.ExecCommand) > 0 {
+		settings = append(settings, Setting{"exec.command", strings.Join(c.ExecCommand, " "), c.Sources["exec_command"]})
+	}
+	if c.Provider == "pseudo" {
+		source := c.Sources["pseudo_ratio"]
+		if source == "" {
+			source = "default"
+		}
+		settings = append(settings, Setting{"pseudo.ratio", strconv.FormatFloat(c.PseudoRatio, 'g', -1, 64), source})
+	}
+	if c.LLMMode != "" {
+		source := c.Sources["cassette"]
+		if source == "" {
+			source = "default"
+		}
+		settings = append(settings,
+			Setting{"llm_mode", c.LLMMode, c.Sources["llm_mode"]},
+			Setting{"cassette", c.CassettePath, source})
+	}
+	if c.MaxTokens > 0 {
+		settings = append(settings, Setting{"max_tokens", strconv.Itoa(c.MaxTokens), c.Sources["max_tokens"]})
+	}
+	if c.MaxCost > 0 {
+		settings = append(settings, Setting{"max_cost", strconv.FormatFloat(c.MaxCost, 'f', 2, 64), c.Sources["max_cost"]})
+	}
+	for _, model := range c.models() {
+		p, ok := c.Pricing[model]
+		if !ok {
+			settings = append(settings, Setting{"pricing." + model, "(not set)", "default"})
+			continue
+		}
+		source := c.Sources["pricing"]
+		if d, ok := DefaultPrices[model]; source == "" || ok && d == p {
+			source = "default"
+		}
+		settings = append(settings, Setting{"pricing." + model, fmt.Sprintf("input %g, output %g USD per 1M tokens", p.Input, p.Output), source})
+	}
+	if c.JudgeModel != "" {
+		settings = append(settings, Setting{"judge_model", c.JudgeModel, c.Sources["judge_model"]})
+	}
+	if len(c.DisabledChecks) > 0 {
+		settings = append(settings, Setting{"disabled_checks", strings.Join(c.DisabledChecks, ","), c.Sources["disabled_checks"]})
+	}
+	if len(c.AllowIdentical) > 0 {
+		settings = append(settings, Setting{"allow_identical", strings.Join(c.AllowIdentical, ","), c.Sources["allow_identical"]})
+	}
+	if len(c.ReferenceLocales) > 0 {
+		settings = append(settings, Setting{"reference_locales", strings.Join(c.ReferenceLocales, ","), c.Sources["reference_locales"]})
+	}
+	for _, kind := range slices.Sorted(maps.Keys(c.LengthRatios)) {
+		source := c.Sources["length"]
+		if source == "" || DefaultLengthRatios[kind] == c.LengthRatios[kind] {
+			source = "default"
+		}
+		settings = append(settings, Setting{"length.ratio." + kind, strconv.FormatFloat(c.LengthRatios[kind], 'g', -1, 64), source})
+	}
+	if len(c.Fonts) > 0 {
+		source := c.Sources["font_size"]
+		if source == "" {
+			source = "default"
+		}
+		settings = append(settings,
+			Setting{"length.fonts", strings.Join(c.Fonts, ","), c.Sources["fonts"]},
+			Setting{"length.font_size", strconv.FormatFloat(c.FontSize, 'g', -1, 64), source})
+	}
+	for _, g := range c.Glossary {
+		settings = append(settings, Setting{"glossary." + g.Term, fmt.Sprint(g.Translations), c.Sources["glossary"]})
+	}
+	for _, locale := range slices.Sorted(maps.Keys(c.LocaleOptions)) {
+		opts := c.LocaleOptions[locale]
+		if opts.Model != "" {
+			settings = append(settings, Setting{"locale." + locale + ".model", opts.Model, c.Sources["locale_options"]})
+		}
+		if opts.Parent != "" {
+			settings = append(settings, Setting{"locale." + locale + ".parent", opts.Parent, c.Sources["locale_options"]})
+		}
+		if opts.Pivot != "" {
+			settings = append(settings, Setting{"locale." + locale + ".pivot", opts.Pivot, c.Sources["locale_options"]})
+		}
+		if opts.Instructions != "" {
+			settings = append(settings, Setting{"locale." + locale + ".instructions", opts.Instructions, c.Sources["locale_options"]})
+		}
+		if opts.StyleGuide != "" {
+			settings = append(settings, Setting{"locale." + locale + ".style_guide", opts.StyleGuide, c.Sources["locale_options"]})
+		}
+		if opts.PostProcess != nil {
+			settings = append(settings, Setting{"locale." + locale + ".post_process", strings.Join(opts.PostProcess, ","), c.Sources["locale_options"]})
+		}
+	}
+	return settings
+}
+
+// Manifest returns the manifest path, falling back to DefaultManifestPath.
+func (c SyncConfig) Manifest() string {
+	if c.ManifestPath == "" {
+		return DefaultManifestPath
+	}
+	return c.ManifestPath
+}
+
+// LocalePath returns the translation file for locale according to
+// LocalesDir and LocalePattern, falling back to the defaults when unset.
+func (c SyncConfig) LocalePath(locale string) string {
+	dir, pattern := c.LocalesDir, c.LocalePattern
+	if dir == "" {
+		dir = DefaultLocalesDir
+	}
+	if pattern == "" {
+		pattern = DefaultLocalePattern
+	}
+	return filepath.Join(dir, strings.ReplaceAll(pattern, "{locale}", locale))
+}
+
+// MergedPath returns the runtime-ready file for locale inside MergedDir,
+// named like the locale file itself.
+func (c SyncConfig) MergedPath(locale string) string {
+	return c.pathIn(c.MergedDir, locale)
+}
+
+// pathIn returns the file for locale inside dir, named like the locale
+// file itself.
+func (c SyncConfig) pathIn(dir, locale string) string {
+	pattern := c.LocalePattern
+	if pattern == "" {
+		pattern = DefaultLocalePattern
+	}
+	return filepath.Join(dir, strings.ReplaceAll(pattern, "{locale}", locale))
+}
+
+// LocaleFiles finds the locale files that already exist under LocalesDir
+// and match LocalePattern, keyed by the locale name as written on disk.
+func (c SyncConfig) LocaleFiles() (map[string]string, error) {
+	matches, err := filepath.Glob(c.LocalePath("*"))
+	if err != nil {
+		return nil, fmt.Errorf("listing locale files: %w", err)
+	}
+	placeholder := regexp.QuoteMeta("{locale}")
+	expr := strings.Replace(regexp.QuoteMeta(c.LocalePath("{locale}")), placeholder, `([^/\\]+)`, 1)
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("compiling locale pattern: %w", err)
+	}
+
+	files := make(map[string]string, len(matches))
+	for _, path := range matches {
+		m := re.FindStringSubmatch(path)
+		if m == nil || c.LocalePath(m[1]) != path {
+			continue
+		}
+		files[m[1]] = path
+	}
+	return files, nil
+}
+
+// StyleGuidePath returns the style guide file for locale and whether it
+// was configured explicitly; the conventional {StyleGuideDir}/{locale}.yaml
+// is optional.
+func (c SyncConfig) StyleGuidePath(locale string) (path string, explicit bool) {
+	if opts := c.LocaleOptions[locale]; opts.StyleGuide != "" {
+		return opts.StyleGuide, true
+	}
+	if c.StyleGuideDir == "" {
+		return "", false
+	}
+	return filepath.Join(c.StyleGuideDir, locale+".yaml"), false
+}
+
+// Checks returns the translation checks enabled for the project.
+func (c SyncConfig) Checks() (checks.Suite, error) {
+	return checks.New(c.DisabledChecks, LanguageCheck)
+}
+
+// LanguageCheck is the name under which wrong-language detection is
+// reported and disabled.
+const LanguageCheck = "language"
+
+// Detector returns the wrong-language detector for translations into
+// locale, or nil when the language check is disabled.
+func (c SyncConfig) Detector(locale string) *langdetect.Detector {
+	if slices.Contains(c.DisabledChecks, LanguageCheck) {
+		return nil
+	}
+	return langdetect.New(locale, c.Source(), c.AllowIdentical)
+}
+
+// PostProcessor returns the normalisation rules for locale: the ones
+// listed in its options, or the defaults for its language.
+func (c SyncConfig) PostProcessor(locale string) (postprocess.Pipeline, error) {
+	return postprocess.New(locale, c.LocaleOptions[locale].PostProcess)
+}
+
+// Judge returns the model that reviews translations.
+func (c SyncConfig) Judge() string {
+	if c.JudgeModel != "" {
+		return c.JudgeModel
+	}
+	return c.OpenAIModel
+}
+
+// ModelFor returns the model to use for locale, honouring per-locale
+// overrides from the project file.
+func (c SyncConfig) ModelFor(locale string) string {
+	if opts, ok := c.LocaleOptions[locale]; ok && opts.Model != "" {
+		return opts.Model
+	}
+	return c.OpenAIModel
+}
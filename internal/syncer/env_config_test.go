@@ -1,29 +1,106 @@
 package syncer
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/config"
 )
 
-func TestGetEnvConfig_Overrides(t *testing.T) {
+func intPtr(n int) *int { return &n }
+
+func TestResolveConfig_Defaults(t *testing.T) {
+	cfg, err := ResolveConfig(nil, Flags{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{DefaultLocale}, cfg.Locales)
+	assert.Equal(t, DefaultBatchSize, cfg.BatchSize)
+	assert.Equal(t, DefaultMaxRetries, cfg.MaxRetries)
+	assert.Equal(t, "default", cfg.Sources["batch_size"])
+}
+
+func TestResolveConfig_EnvOverrides(t *testing.T) {
 	t.Setenv("OPENAI_MODEL", "gpt-4")
 	t.Setenv("SYNC_BATCH_SIZE", "50")
 	t.Setenv("SYNC_MAX_RETRIES", "5")
 	t.Setenv("SYNC_DEFAULT_LOCALES", "en,fr")
 
-	cfg := GetEnvConfig([]string{"pidgin"}, 200, 3)
+	cfg, err := ResolveConfig(nil, Flags{})
+	require.NoError(t, err)
 
 	assert.Equal(t, "gpt-4", cfg.OpenAIModel)
 	assert.Equal(t, 50, cfg.BatchSize)
 	assert.Equal(t, 5, cfg.MaxRetries)
 	assert.Equal(t, []string{"en", "fr"}, cfg.Locales)
+	assert.Equal(t, "env SYNC_DEFAULT_LOCALES", cfg.Sources["locales"])
 }
 
-func TestGetEnvConfig_FlagPrecedence(t *testing.T) {
+func TestResolveConfig_Precedence(t *testing.T) {
+	project := &config.Project{
+		Path:     "nogodey.yaml",
+		Locales:  []string{"de"},
+		Model:    "file-model",
+		Batching: config.Batching{Size: intPtr(10), MaxRetries: intPtr(2)},
+	}
 	t.Setenv("SYNC_BATCH_SIZE", "99")
-	cfg := GetEnvConfig([]string{"en"}, 123, 7)
-	// Env overrides batch size (per current implementation), but maxRetries keeps flag value since no env override.
-	assert.Equal(t, 99, cfg.BatchSize)
-	assert.Equal(t, 7, cfg.MaxRetries)
+	t.Setenv("OPENAI_MODEL", "env-model")
+
+	cfg, err := ResolveConfig(project, Flags{BatchSize: intPtr(123)})
+	require.NoError(t, err)
+
+	// flags > env > file > defaults
+	assert.Equal(t, 123, cfg.BatchSize)
+	assert.Equal(t, "flag --batch-size", cfg.Sources["batch_size"])
+	assert.Equal(t, "env-model", cfg.OpenAIModel)
+	assert.Equal(t, "env OPENAI_MODEL", cfg.Sources["model"])
+	assert.Equal(t, 2, cfg.MaxRetries)
+	assert.Equal(t, "file nogodey.yaml", cfg.Sources["max_retries"])
+	assert.Equal(t, []string{"de"}, cfg.Locales)
+}
+
+func TestResolveConfig_SpendingLimits(t *testing.T) {
+	maxCost := 3.0
+	project := &config.Project{
+		Path:     "nogodey.yaml",
+		Batching: config.Batching{MaxTokens: intPtr(1000), MaxCost: &maxCost},
+		Pricing:  map[string]config.Price{"local-llm": {Input: 0.1, Output: 0.2}},
+	}
+	t.Setenv("SYNC_MAX_COST", "7.5")
+
+	cfg, err := ResolveConfig(project, Flags{})
+	require.NoError(t, err)
+	assert.Equal(t, 1000, cfg.MaxTokens)
+	assert.Equal(t, 7.5, cfg.MaxCost)
+	assert.Equal(t, "env SYNC_MAX_COST", cfg.Sources["max_cost"])
+	assert.Equal(t, config.Price{Input: 0.1, Output: 0.2}, cfg.Pricing["local-llm"])
+	assert.Equal(t, DefaultPrices["gpt-4o"], cfg.Pricing["gpt-4o"], "built-in prices are kept")
+
+	t.Setenv("SYNC_MAX_COST", "lots")
+	_, err = ResolveConfig(nil, Flags{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SYNC_MAX_COST")
+}
+
+func TestResolveConfig_BadEnv(t *testing.T) {
+	t.Setenv("SYNC_BATCH_SIZE", "lots")
+	_, err := ResolveConfig(nil, Flags{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SYNC_BATCH_SIZE")
+}
+
+func TestResolveConfig_Paths(t *testing.T) {
+	cfg, err := ResolveConfig(nil, Flags{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultManifestPath, cfg.ManifestPath)
+	assert.Equal(t, filepath.Join("js", "locales", "fr.json"), cfg.LocalePath("fr"))
+
+	t.Setenv("SYNC_MANIFEST", "build/messages.json")
+	t.Setenv("SYNC_LOCALES_DIR", "src/i18n")
+	t.Setenv("SYNC_LOCALE_PATTERN", "{locale}/common.json")
+	cfg, err = ResolveConfig(nil, Flags{})
+	require.NoError(t, err)
+	assert.Equal(t, "build/messages.json", cfg.Manifest())
+	assert.Equal(t, filepath.Join("src", "i18n", "fr", "common.json"), cfg.LocalePath("fr"))
 }
@@ -0,0 +1,234 @@
+package syncer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/config"
+)
+
+// ModelRef names a model and the provider serving it.
+type ModelRef struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+func (r ModelRef) String() string { return r.Provider + "/" + r.Model }
+
+// ParseModelRef reads "provider/model", or a bare model served by
+// defaultProvider. Model names may contain slashes themselves, so only a
+// known provider is split off.
+func ParseModelRef(s, defaultProvider string) ModelRef {
+	if provider, model, ok := strings.Cut(s, "/"); ok && slices.Contains(config.Providers, provider) {
+		return ModelRef{Provider: provider, Model: model}
+	}
+	return ModelRef{Provider: defaultProvider, Model: s}
+}
+
+// provider returns the configured provider, DefaultProvider when unset.
+func (c SyncConfig) provider() string {
+	if c.Provider == "" {
+		return DefaultProvider
+	}
+	return c.Provider
+}
+
+// modelChain returns the models to try for lc in order: the locale's model
+// and then the fallbacks. An explicit lc.model, as for candidates, is
+// tried alone.
+func (c SyncConfig) modelChain(lc localeContext) []ModelRef {
+	provider := c.provider()
+	if lc.model != "" {
+		return []ModelRef{{provider, lc.model}}
+	}
+	chain := []ModelRef{{provider, c.ModelFor(lc.locale)}}
+	for _, f := range c.Fallbacks {
+		if ref := ParseModelRef(f, provider); !slices.Contains(chain, ref) {
+			chain = append(chain, ref)
+		}
+	}
+	return chain
+}
+
+// translationOnly are the providers that translate the data block of a
+// prompt instead of following the prompt.
+var translationOnly = []string{"pseudo", "exec"}
+
+// requireChat returns an error when a model of chain is served by a
+// provider that only translates. Judge and back-translation prompts ask
+// for something else, and a translation would be read as the answer.
+func requireChat(chain []ModelRef, command string) error {
+	for _, ref := range chain {
+		if slices.Contains(translationOnly, ref.Provider) {
+			return fmt.Errorf("%s needs a chat model, but provider %s only translates; run it with --provider openai", command, ref.Provider)
+		}
+	}
+	return nil
+}
+
+// callWithRetries translates batch with the first model of the chain that
+// answers, see callModel. A model whose retries are exhausted without any
+// translation hands the batch to the next one.
+func callWithRetries(log *logger.Logger, client ChatClient, batch []Message, build func([]Message, localeContext) string, lc localeContext, cfg SyncConfig) (map[string]string, error) {
+	chain := cfg.modelChain(lc)
+	var errs []error
+	for i, ref := range chain {
+		c := client
+		if ref.Provider != chain[0].Provider {
+			c = clientFor(client, cfg, ref.Provider)
+		}
+		if i > 0 {
+			log.Warn("falling back to next model", "locale", lc.locale, "model", ref.String(), "failed_model", chain[i-1].String(), "keys", len(batch))
+		}
+		mlc := lc
+		mlc.model = ref.Model
+		mlc.deterministic = ref.Provider == "pseudo"
+		translations, err := callModel(log, c, batch, build, mlc, cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+			continue
+		}
+		lc.summary.record(translations, ref, i > 0)
+		return translations, nil
+	}
+	if len(errs) == 1 {
+		return nil, errors.Unwrap(errs[0])
+	}
+	return nil, errors.Join(errs...)
+}
+
+// localeSummary counts the translations a locale's sync produced, the
+// models that produced them and the tokens it used.
+type localeSummary struct {
+	locale     string
+	translated int
+	// fallback counts translations produced by a fallback model.
+	fallback int
+	// skipped counts keys left for later because a spending limit was
+	// reached.
+	skipped int
+	// models records which model produced each new translation.
+	models map[string]ModelRef
+	usage  usage
+}
+
+func newLocaleSummary(locale string) *localeSummary {
+	return &localeSummary{locale: locale, models: make(map[string]ModelRef), usage: make(usage)}
+}
+
+// record notes the model of translations. A nil summary records nothing.
+func (s *localeSummary) record(translations map[string]string, ref ModelRef, fallback bool) {
+	if s == nil {
+		return
+	}
+	for k := range translations {
+		s.models[k] = ref
+	}
+	s.translated += len(translations)
+	if fallback {
+		s.fallback += len(translations)
+	}
+}
+
+// byModel counts the translations per model.
+func (s *localeSummary) byModel() map[string]int {
+	counts := make(map[string]int)
+	for _, ref := range s.models {
+		counts[ref.String()]++
+	}
+	return counts
+}
+
+// logSummary reports what the sync produced and cost per locale and for
+// the run, and how often the fallback models had to step in.
+func logSummary(log *logger.Logger, cfg SyncConfig, summaries []*localeSummary) {
+	fallback, skipped := 0, 0
+	run := make(usage)
+	for _, s := range summaries {
+		counts := s.byModel()
+		models := make([]string, 0, len(counts))
+		for _, m := range slices.Sorted(maps.Keys(counts)) {
+			models = append(models, fmt.Sprintf("%s=%d", m, counts[m]))
+		}
+		tokens := s.usage.total()
+		cost, _ := cfg.cost(s.usage)
+		log.Info("locale summary", "locale", s.locale, "translated", s.translated, "models", strings.Join(models, ", "), "fallback_translations", s.fallback,
+			"prompt_tokens", tokens.Prompt, "completion_tokens", tokens.Completion, "cost_usd", fmt.Sprintf("%.4f", cost))
+		fallback += s.fallback
+		skipped += s.skipped
+		for m, t := range s.usage {
+			run.add(m, t)
+		}
+	}
+	tokens := run.total()
+	cost, unpriced := cfg.cost(run)
+	log.Info("run summary", "locales", len(summaries), "prompt_tokens", tokens.Prompt, "completion_tokens", tokens.Completion, "cost_usd", fmt.Sprintf("%.4f", cost))
+	if len(unpriced) > 0 {
+		log.Warn("no price for models, their cost is not included", "models", strings.Join(unpriced, ", "), "help", "Set their price under pricing in the project file")
+	}
+	if fallback > 0 {
+		log.Warn("fallback models produced translations", "count", fallback, "help", "See the models files for which keys to review")
+	}
+	if skipped > 0 {
+		log.Warn("spending limit left keys untranslated", "count", skipped, "help", "Run sync again to continue, or raise --max-tokens / --max-cost")
+	}
+}
+
+// ModelsPath returns the file recording which model produced each
+// translation of locale, named like the locale file inside ModelsDir.
+func (c SyncConfig) ModelsPath(locale string) string {
+	return c.pathIn(c.ModelsDir, locale)
+}
+
+// ReadModels reads a models file; a missing file records nothing.
+func ReadModels(path string) (map[string]ModelRef, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]ModelRef), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading models: %w", err)
+	}
+	var models map[string]ModelRef
+	if err := json.Unmarshal(data, &models); err != nil {
+		return nil, fmt.Errorf("parsing models %s: %w", path, err)
+	}
+	if models == nil {
+		models = make(map[string]ModelRef)
+	}
+	return models, nil
+}
+
+// writeModels merges the models of this sync into the models file of the
+// summary's locale. Without fallbacks every key comes from the one model,
+// so there is nothing to record; an empty ModelsDir disables the file too.
+func writeModels(cfg SyncConfig, s *localeSummary) error {
+	if len(cfg.Fallbacks) == 0 || cfg.ModelsDir == "" || len(s.models) == 0 {
+		return nil
+	}
+	path := cfg.ModelsPath(s.locale)
+	all, err := ReadModels(path)
+	if err != nil {
+		return err
+	}
+	maps.Copy(all, s.models)
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling models: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing models %s: %w", path, err)
+	}
+	return nil
+}
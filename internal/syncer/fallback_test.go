@@ -0,0 +1,97 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// outageClient fails for the models in down and answers for the rest.
+type outageClient struct {
+	down   map[string]bool
+	models []string
+}
+
+func (o *outageClient) CreateChatCompletion(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	o.models = append(o.models, req.Model)
+	if o.down[req.Model] {
+		return openai.ChatCompletionResponse{}, errors.New("503 service unavailable")
+	}
+	return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"save": "Speichern"}`}}}}, nil
+}
+
+func TestParseModelRef(t *testing.T) {
+	assert.Equal(t, ModelRef{"openai", "gpt-4o"}, ParseModelRef("openai/gpt-4o", "openai"))
+	assert.Equal(t, ModelRef{"openai", "gpt-4o"}, ParseModelRef("gpt-4o", "openai"))
+	assert.Equal(t, ModelRef{"openai", "meta-llama/llama-3"}, ParseModelRef("meta-llama/llama-3", "openai"), "only known providers are split off")
+	assert.Equal(t, "openai/gpt-4o", ModelRef{"openai", "gpt-4o"}.String())
+}
+
+func TestModelChain(t *testing.T) {
+	cfg := SyncConfig{OpenAIModel: "primary", Fallbacks: []string{"backup", "openai/primary", "openai/last"}}
+	assert.Equal(t, []ModelRef{{"openai", "primary"}, {"openai", "backup"}, {"openai", "last"}}, cfg.modelChain(localeContext{locale: "de"}))
+	assert.Equal(t, []ModelRef{{"openai", "chosen"}}, cfg.modelChain(localeContext{locale: "de", model: "chosen"}), "explicit models are not replaced")
+}
+
+func TestSyncLocale_FallsBackToNextModel(t *testing.T) {
+	dir := t.TempDir()
+	client := &outageClient{down: map[string]bool{"primary": true}}
+	cfg := SyncConfig{
+		SourceLocale: "en",
+		BatchSize:    10,
+		MaxRetries:   2,
+		OpenAIModel:  "primary",
+		Fallbacks:    []string{"backup"},
+		LocalesDir:   dir,
+		ModelsDir:    filepath.Join(dir, "models"),
+		Client:       client,
+	}
+
+	summary, err := syncLocale(logger.New(), []Message{{Key: "save", Default: "Save"}}, "de", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"primary", "primary", "backup"}, client.models)
+	assert.Equal(t, 1, summary.translated)
+	assert.Equal(t, 1, summary.fallback)
+	assert.Equal(t, map[string]int{"openai/backup": 1}, summary.byModel())
+
+	got, err := locales.Read(cfg.LocalePath("de"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"save": "Speichern"}, got)
+	models, err := ReadModels(cfg.ModelsPath("de"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ModelRef{"save": {"openai", "backup"}}, models)
+}
+
+func TestSyncLocale_NoModelsFileWithoutFallbacks(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		SourceLocale: "en",
+		BatchSize:    10,
+		MaxRetries:   1,
+		OpenAIModel:  "primary",
+		LocalesDir:   dir,
+		ModelsDir:    filepath.Join(dir, "models"),
+		Client:       &outageClient{},
+	}
+
+	_, err := syncLocale(logger.New(), []Message{{Key: "save", Default: "Save"}}, "de", cfg)
+	require.NoError(t, err)
+	assert.NoFileExists(t, cfg.ModelsPath("de"))
+}
+
+func TestCallWithRetries_ChainExhausted(t *testing.T) {
+	client := &outageClient{down: map[string]bool{"primary": true, "backup": true}}
+	cfg := SyncConfig{MaxRetries: 1, OpenAIModel: "primary", Fallbacks: []string{"backup"}, Client: client}
+
+	_, err := translateBatch(logger.New(), client, []Message{{Key: "save", Default: "Save"}}, localeContext{locale: "de"}, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "openai/primary")
+	assert.Contains(t, err.Error(), "openai/backup")
+}
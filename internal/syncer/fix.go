@@ -0,0 +1,85 @@
+package syncer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/mask"
+	"github.com/you/nogodey/internal/postprocess"
+)
+
+// Fix is one translation changed by post-processing.
+type Fix struct {
+	Key    string
+	Before string
+	After  string
+}
+
+// FixLocale applies the locale's post-processing rules to every
+// translation already in its file, e.g. after changing the rules. With
+// dryRun set the file is left untouched. Fixes are sorted by key.
+func FixLocale(cfg SyncConfig, locale string, dryRun bool) ([]Fix, error) {
+	post, err := cfg.PostProcessor(locale)
+	if err != nil {
+		return nil, err
+	}
+	path := cfg.LocalePath(locale)
+	translations, err := locales.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading locale file %s: %w", path, err)
+	}
+
+	var fixes []Fix
+	for _, key := range slices.Sorted(maps.Keys(translations)) {
+		before := translations[key]
+		after, err := applyPost(post, before)
+		if err != nil {
+			logger.New().Warn("post-processing skipped", "locale", locale, "key", key, "error", err.Error())
+			continue
+		}
+		if after != before {
+			fixes = append(fixes, Fix{Key: key, Before: before, After: after})
+			translations[key] = after
+		}
+	}
+	if len(fixes) == 0 || dryRun {
+		return fixes, nil
+	}
+	if err := locales.Write(path, translations); err != nil {
+		return fixes, fmt.Errorf("writing locale file %s: %w", path, err)
+	}
+	return fixes, nil
+}
+
+// applyPost runs post over a translation that passed the checks. The rules
+// only change text, so a result whose tags, placeholders or ICU structure
+// differ from the translation's is a bug; the translation is then returned
+// as it was, with the reason.
+func applyPost(post postprocess.Pipeline, translation string) (string, error) {
+	after := post.Apply(translation)
+	if after == translation {
+		return after, nil
+	}
+	m := mask.New(translation)
+	if _, err := m.Unmask(m.Apply(after)); err != nil || mask.New(after).Len() != m.Len() {
+		return translation, fmt.Errorf("post-processing changed the tags or placeholders of %q", translation)
+	}
+	if braces(after) != braces(translation) {
+		return translation, fmt.Errorf("post-processing changed the ICU structure of %q", translation)
+	}
+	return after, nil
+}
+
+// braces returns the braces of s in order, the skeleton of an ICU message.
+func braces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '{' || r == '}' {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,69 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/postprocess"
+)
+
+func TestFixLocale(t *testing.T) {
+	cfg := SyncConfig{LocalesDir: t.TempDir()}
+	require.NoError(t, locales.Write(cfg.LocalePath("fr"), map[string]string{
+		"ready": "Prêt?",
+		"saved": "Enregistré",
+	}))
+
+	fixes, err := FixLocale(cfg, "fr", true)
+	require.NoError(t, err)
+	assert.Equal(t, []Fix{{Key: "ready", Before: "Prêt?", After: "Prêt ?"}}, fixes)
+	got, err := locales.Read(cfg.LocalePath("fr"))
+	require.NoError(t, err)
+	assert.Equal(t, "Prêt?", got["ready"], "dry run leaves the file alone")
+
+	_, err = FixLocale(cfg, "fr", false)
+	require.NoError(t, err)
+	got, err = locales.Read(cfg.LocalePath("fr"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ready": "Prêt ?", "saved": "Enregistré"}, got)
+}
+
+func TestFixLocale_ConfiguredRules(t *testing.T) {
+	cfg := SyncConfig{
+		LocalesDir:    t.TempDir(),
+		LocaleOptions: map[string]config.LocaleOptions{"fr": {PostProcess: []string{"ellipsis"}}},
+	}
+	require.NoError(t, locales.Write(cfg.LocalePath("fr"), map[string]string{"wait": "Attendez..."}))
+	fixes, err := FixLocale(cfg, "fr", true)
+	require.NoError(t, err)
+	require.Len(t, fixes, 1)
+	assert.Equal(t, "Attendez…", fixes[0].After, "no french-spacing when rules are listed explicitly")
+
+	cfg.LocaleOptions["fr"] = config.LocaleOptions{PostProcess: []string{"typo"}}
+	_, err = FixLocale(cfg, "fr", true)
+	require.Error(t, err)
+}
+
+func TestFixLocale_ICUMessage(t *testing.T) {
+	cfg := SyncConfig{LocalesDir: t.TempDir()}
+	require.NoError(t, locales.Write(cfg.LocalePath("es"), map[string]string{
+		"delete": "{count, plural, one {Borrar # archivo?} other {Borrar # archivos?}}",
+	}))
+	fixes, err := FixLocale(cfg, "es", true)
+	require.NoError(t, err)
+	require.Len(t, fixes, 1)
+	assert.Equal(t, "{count, plural, one {¿Borrar # archivo?} other {¿Borrar # archivos?}}", fixes[0].After)
+}
+
+func TestApplyPost_KeepsStructure(t *testing.T) {
+	post, err := postprocess.New("es", nil)
+	require.NoError(t, err)
+	got, err := applyPost(post, "Hola <b>{name}</b>!")
+	require.NoError(t, err)
+	assert.Equal(t, "¡Hola <b>{name}</b>!", got)
+
+	assert.Equal(t, "{{}}", braces("{a, select, x {y}} z"))
+}
@@ -0,0 +1,70 @@
+package syncer
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/you/nogodey/internal/font"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/mask"
+)
+
+// GlyphIssue lists the characters of one translation that none of the
+// app's fonts can render.
+type GlyphIssue struct {
+	Locale  string
+	Path    string
+	Key     string
+	Missing []rune
+}
+
+func (g GlyphIssue) String() string {
+	chars := make([]string, len(g.Missing))
+	for i, r := range g.Missing {
+		chars[i] = fmt.Sprintf("%U %q", r, r)
+	}
+	return fmt.Sprintf("%s: %s: no glyph for %s", g.Path, g.Key, strings.Join(chars, ", "))
+}
+
+// CheckGlyphs reports translations in the target locales that use
+// characters missing from every font in fonts, as they would render as
+// boxes. Tags, ICU arguments and invisible formatting characters are not
+// rendered and are skipped. Locale files that do not exist are skipped.
+func CheckGlyphs(cfg SyncConfig, targets []string, fonts []*font.Font) ([]GlyphIssue, error) {
+	var out []GlyphIssue
+	for _, locale := range targets {
+		path := cfg.LocalePath(locale)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		translations, err := locales.Read(path)
+		if err != nil {
+			return out, fmt.Errorf("reading locale file %s: %w", path, err)
+		}
+		for _, key := range slices.Sorted(maps.Keys(translations)) {
+			if missing := missingGlyphs(translations[key], fonts); len(missing) > 0 {
+				out = append(out, GlyphIssue{Locale: locale, Path: path, Key: key, Missing: missing})
+			}
+		}
+	}
+	return out, nil
+}
+
+// missingGlyphs returns the distinct characters of s that no font has, in
+// order of appearance.
+func missingGlyphs(s string, fonts []*font.Font) []rune {
+	var missing []rune
+	for _, r := range mask.Strip(s) {
+		if unicode.IsControl(r) || unicode.In(r, unicode.Cf, unicode.Variation_Selector) || slices.Contains(missing, r) {
+			continue
+		}
+		if !slices.ContainsFunc(fonts, func(f *font.Font) bool { return f.Has(r) }) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,31 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/font"
+	"github.com/you/nogodey/internal/font/fonttest"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func TestCheckGlyphs(t *testing.T) {
+	latin, err := font.Parse(fonttest.SFNT(map[string][]byte{"cmap": fonttest.Cmap(fonttest.Chars("abcdefghijklmnopqrstuvwxyz ."))}))
+	require.NoError(t, err)
+	accents, err := font.Parse(fonttest.SFNT(map[string][]byte{"cmap": fonttest.Cmap(fonttest.Chars("ạ"))}))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	cfg := SyncConfig{LocalesDir: dir}
+	require.NoError(t, locales.Write(cfg.LocalePath("vi"), map[string]string{
+		"ok":      "xin chạo.",
+		"missing": "<b>{count}</b> ngươi ươ\u200d",
+	}))
+
+	issues, err := CheckGlyphs(cfg, []string{"vi", "yo"}, []*font.Font{latin, accents})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, []rune{'ư', 'ơ'}, issues[0].Missing, "tags, arguments and joiners are skipped")
+	assert.Equal(t, cfg.LocalePath("vi")+`: missing: no glyph for U+01B0 'ư', U+01A1 'ơ'`, issues[0].String())
+}
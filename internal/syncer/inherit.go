@@ -0,0 +1,141 @@
+package syncer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/llm"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// ParentOf returns the locale that locale inherits from, or "" when it is
+// a base locale that falls back straight to the source strings.
+func (c SyncConfig) ParentOf(locale string) string {
+	return c.LocaleOptions[locale].Parent
+}
+
+// Ancestors returns the inheritance chain of locale, nearest parent first,
+// e.g. fr-CA → [fr]. A cycle ends the chain; Validate reports it.
+func (c SyncConfig) Ancestors(locale string) []string {
+	var chain []string
+	seen := map[string]bool{locale: true}
+	for p := c.ParentOf(locale); p != "" && !seen[p]; p = c.ParentOf(p) {
+		seen[p] = true
+		chain = append(chain, p)
+	}
+	return chain
+}
+
+// orderByDependency returns list reordered so that a locale another one
+// builds on, its parent or its pivot, is synced first and its fresh
+// translations are available. Otherwise the original order is kept.
+func orderByDependency(list []string, cfg SyncConfig) []string {
+	inList := make(map[string]bool, len(list))
+	for _, l := range list {
+		inList[l] = true
+	}
+	done := make(map[string]bool, len(list))
+	ordered := make([]string, 0, len(list))
+	var visit func(string)
+	visit = func(l string) {
+		if done[l] {
+			return
+		}
+		done[l] = true
+		for _, a := range cfg.Ancestors(l) {
+			if inList[a] {
+				visit(a)
+				break
+			}
+		}
+		if p := cfg.PivotFor(l); inList[p] {
+			visit(p)
+		}
+		ordered = append(ordered, l)
+	}
+	for _, l := range list {
+		visit(l)
+	}
+	return ordered
+}
+
+// readAncestors overlays the translations of every ancestor of locale,
+// the nearest one winning. Missing ancestor files are skipped.
+func readAncestors(log *logger.Logger, cfg SyncConfig, locale string) map[string]string {
+	chain := cfg.Ancestors(locale)
+	inherited := make(map[string]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		translations, err := locales.Read(cfg.LocalePath(chain[i]))
+		if err != nil {
+			log.Warn("failed to read parent locale file", "locale", locale, "parent", chain[i], "error", err.Error())
+			continue
+		}
+		for k, v := range translations {
+			inherited[k] = v
+		}
+	}
+	return inherited
+}
+
+// splitInherited separates the missing keys another locale (a parent or a
+// pivot) already translated from those that have to start from the source.
+func splitInherited(missing []Message, known map[string]string) (covered, rest []Message) {
+	for _, m := range missing {
+		if _, ok := known[m.Key]; ok {
+			covered = append(covered, m)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+	return covered, rest
+}
+
+func adaptBatch(log *logger.Logger, client ChatClient, batch []Message, lc localeContext, cfg SyncConfig) (map[string]string, error) {
+	build := func(b []Message, lc localeContext) string { return buildAdaptPrompt(b, lc, cfg) }
+	return callWithRetries(log, client, batch, build, lc, cfg)
+}
+
+// buildAdaptPrompt asks the model to adjust existing parent translations
+// to a regional variant rather than translate from scratch.
+func buildAdaptPrompt(batch []Message, lc localeContext, cfg SyncConfig) string {
+	locale, parent := lc.locale, lc.parent
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("These UI strings are already translated into %s (%s). Adapt the \"text\" of each for %s (%s): change only what differs in that region, such as spelling, vocabulary and conventions, and return a translation unchanged when nothing needs to change. Preserve placeholders. The original %s \"source\" is given for context.\n\n",
+		locales.DisplayName(parent), parent, locales.DisplayName(locale), locale, locales.DisplayName(cfg.Source())))
+	writeGuidance(&b, batch, locale, cfg)
+	writeReferenceNote(&b, lc)
+	writeData(&b, batch, lc, func(m Message) llm.DataItem {
+		return llm.DataItem{Text: lc.inherited[m.Key], Source: m.Default}
+	})
+	return b.String()
+}
+
+// writeMerged writes the runtime-ready view of locale to cfg.MergedDir:
+// every manifest key resolved through locale → ancestors → source text.
+// Nothing is written when MergedDir is unset.
+func writeMerged(log *logger.Logger, messages []Message, locale string, own, inherited map[string]string, cfg SyncConfig) error {
+	if cfg.MergedDir == "" {
+		return nil
+	}
+	merged := make(map[string]string, len(messages))
+	fallbacks := 0
+	for _, m := range messages {
+		switch v, ok := own[m.Key]; {
+		case ok:
+			merged[m.Key] = v
+		case inherited[m.Key] != "":
+			merged[m.Key] = inherited[m.Key]
+			fallbacks++
+		default:
+			merged[m.Key] = m.Default
+			fallbacks++
+		}
+	}
+	path := cfg.MergedPath(locale)
+	if err := locales.Write(path, merged); err != nil {
+		return fmt.Errorf("writing merged locale file %s: %w", path, err)
+	}
+	log.Info("wrote merged locale file", "locale", locale, "path", path, "keys", len(merged), "fallbacks", fallbacks)
+	return nil
+}
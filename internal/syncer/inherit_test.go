@@ -0,0 +1,96 @@
+package syncer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// recordingClient answers every prompt with a fixed response and keeps the
+// prompts and system messages it was sent.
+type recordingClient struct {
+	reply   func(prompt string) string
+	prompts []string
+	systems []string
+}
+
+func (r *recordingClient) CreateChatCompletion(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	r.prompts = append(r.prompts, prompt)
+	r.systems = append(r.systems, req.Messages[0].Content)
+	return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: r.reply(prompt)}}}}, nil
+}
+
+func TestOrderByParent(t *testing.T) {
+	cfg := SyncConfig{LocaleOptions: map[string]config.LocaleOptions{
+		"fr-CA": {Parent: "fr"},
+		"pt-PT": {Parent: "pt-BR"},
+	}}
+	got := orderByDependency([]string{"fr-CA", "de", "pt-PT", "fr"}, cfg)
+	assert.Equal(t, []string{"fr", "fr-CA", "de", "pt-PT"}, got)
+}
+
+func TestValidate_ParentCycle(t *testing.T) {
+	cfg := SyncConfig{LocaleOptions: map[string]config.LocaleOptions{
+		"fr-CA": {Parent: "fr"},
+		"fr":    {Parent: "fr-CA"},
+	}}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "inheritance cycle")
+}
+
+func TestSyncLocale_SeedsFromParent(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		BatchSize: 10, MaxRetries: 1,
+		LocalesDir:    dir,
+		MergedDir:     filepath.Join(dir, "merged"),
+		LocaleOptions: map[string]config.LocaleOptions{"fr-CA": {Parent: "fr"}},
+	}
+	require.NoError(t, locales.Write(cfg.LocalePath("fr"), map[string]string{"email": "Courriel ou e-mail"}))
+
+	client := &recordingClient{reply: func(prompt string) string {
+		if strings.Contains(prompt, "for Canadian French (fr-CA)") {
+			return `email: "Courriel"`
+		}
+		return `weekend: "Fin de semaine"`
+	}}
+	cfg.Client = client
+
+	msgs := []Message{{Key: "email", Default: "Email"}, {Key: "weekend", Default: "Weekend"}, {Key: "ok", Default: "OK"}}
+	require.NoError(t, locales.Write(cfg.LocalePath("fr-CA"), map[string]string{"ok": "OK"}))
+
+	_, err := syncLocale(logger.New(), msgs, "fr-CA", cfg)
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], `"email": {"text":"Courriel ou e-mail","source":"Email"}`)
+	assert.NotContains(t, client.prompts[0], "weekend")
+
+	got, err := locales.Read(cfg.LocalePath("fr-CA"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"email": "Courriel", "weekend": "Fin de semaine", "ok": "OK"}, got)
+
+	merged, err := locales.Read(cfg.MergedPath("fr-CA"))
+	require.NoError(t, err)
+	assert.Len(t, merged, 3)
+}
+
+func TestWriteMerged_Fallbacks(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{MergedDir: dir}
+	msgs := []Message{{Key: "a", Default: "A"}, {Key: "b", Default: "B"}, {Key: "c", Default: "C"}}
+
+	require.NoError(t, writeMerged(logger.New(), msgs, "fr-CA", map[string]string{"a": "a-CA"}, map[string]string{"a": "a-fr", "b": "b-fr"}, cfg))
+	got, err := locales.Read(cfg.MergedPath("fr-CA"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "a-CA", "b": "b-fr", "c": "C"}, got)
+}
@@ -0,0 +1,71 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/you/nogodey/internal/checks"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/messages"
+)
+
+// LintIssue is a check failure in a locale file.
+type LintIssue struct {
+	Locale string
+	Path   string
+	checks.Issue
+}
+
+func (i LintIssue) String() string {
+	return i.Path + ": " + i.Issue.String()
+}
+
+// Lint runs the translation checks over the existing translations of each
+// locale in targets, in manifest order. Locale files that do not exist yet
+// are skipped; nothing is written.
+func Lint(cfg SyncConfig, targets []string) ([]LintIssue, error) {
+	suite, err := cfg.Checks()
+	if err != nil {
+		return nil, err
+	}
+	all, err := messages.Read(cfg.Manifest())
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	msgs, _, err := messages.Validate(all, cfg.CollisionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []LintIssue
+	for _, locale := range targets {
+		if locale == cfg.Source() {
+			continue
+		}
+		detector := cfg.Detector(locale)
+		path := cfg.LocalePath(locale)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		translations, err := locales.Read(path)
+		if err != nil {
+			return out, fmt.Errorf("reading locale file %s: %w", path, err)
+		}
+		for _, m := range msgs {
+			t, ok := translations[m.Key]
+			if !ok {
+				continue
+			}
+			for _, issue := range suite.Run(m.Key, m.Default, t) {
+				out = append(out, LintIssue{Locale: locale, Path: path, Issue: issue})
+			}
+			for _, msg := range detector.Check(m.Key, m.Default, t) {
+				out = append(out, LintIssue{Locale: locale, Path: path, Issue: checks.Issue{Key: m.Key, Check: LanguageCheck, Msg: msg}})
+			}
+			if over := cfg.overBudget(m, t); over != "" {
+				out = append(out, LintIssue{Locale: locale, Path: path, Issue: checks.Issue{Key: m.Key, Check: "length", Msg: over}})
+			}
+		}
+	}
+	return out, nil
+}
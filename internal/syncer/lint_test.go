@@ -0,0 +1,68 @@
+package syncer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func writeManifest(t *testing.T, dir string, msgs []Message) string {
+	t.Helper()
+	path := filepath.Join(dir, "messages.json")
+	data, err := json.Marshal(msgs)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		LocalesDir:   dir,
+		ManifestPath: writeManifest(t, dir, []Message{{Key: "delete", Default: "Delete?"}, {Key: "help", Default: "Mail help@example.com"}}),
+	}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), map[string]string{"delete": "Löschen", "help": "Schreib an help@example.com"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("en"), map[string]string{"delete": "Delete"}))
+
+	issues, err := Lint(cfg, []string{"de", "en", "fr"})
+	require.NoError(t, err)
+	require.Len(t, issues, 1, "the source locale and missing files are skipped")
+	assert.Equal(t, cfg.LocalePath("de")+`: delete: [punctuation] missing terminal '?'`, issues[0].String())
+
+	cfg.DisabledChecks = []string{"punctuation"}
+	issues, err = Lint(cfg, []string{"de"})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestTranslateBatch_RetriesFailedChecks(t *testing.T) {
+	client := &recordingClient{reply: func(prompt string) string {
+		if !strings.Contains(prompt, "had problems") {
+			return "delete: \"Löschen\"\nsave: \"Speichern\""
+		}
+		return `delete: "Löschen?"`
+	}}
+	batch := []Message{{Key: "delete", Default: "Delete?"}, {Key: "save", Default: "Save"}}
+	cfg := SyncConfig{MaxRetries: 2, OpenAIModel: "test"}
+
+	got, err := translateBatch(logger.New(), client, batch, localeContext{locale: "de"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"delete": "Löschen?", "save": "Speichern"}, got)
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[1], `- "delete": "[punctuation] missing terminal '?'"`)
+	assert.NotContains(t, client.prompts[1], `"save"`, "only failing keys are sent again")
+
+	// Keys still failing after the last attempt are dropped.
+	cfg.MaxRetries = 1
+	client.prompts = nil
+	got, err = translateBatch(logger.New(), client, batch, localeContext{locale: "de"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"save": "Speichern"}, got)
+}
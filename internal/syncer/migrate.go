@@ -0,0 +1,93 @@
+package syncer
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/you/nogodey/internal/locales"
+)
+
+// Migration describes one locale file renamed to its canonical name.
+type Migration struct {
+	From string
+	To   string
+	// Merged is set when a file with the canonical name already existed;
+	// its translations win over the ones being moved in.
+	Merged bool
+	// Err is set for files that were left alone, e.g. an unknown locale.
+	Err error
+}
+
+// MigrateLocales renames locale files written under friendly or
+// non-canonical names ("pidgin.json", "pt_br.json") to their BCP 47 names
+// ("pcm.json", "pt-BR.json"). With dryRun set nothing is written.
+func MigrateLocales(cfg SyncConfig, dryRun bool) ([]Migration, error) {
+	files, err := cfg.LocaleFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Migration
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		from := files[name]
+		canonical, err := locales.Canonicalize(name)
+		if err != nil {
+			out = append(out, Migration{From: from, Err: err})
+			continue
+		}
+		if canonical == name {
+			continue
+		}
+		m := Migration{From: from, To: cfg.LocalePath(canonical)}
+		if _, err := os.Stat(m.To); err == nil {
+			m.Merged = true
+		}
+		if !dryRun {
+			if err := migrateFile(m); err != nil {
+				return out, fmt.Errorf("migrating %s: %w", from, err)
+			}
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func migrateFile(m Migration) error {
+	if !m.Merged {
+		if err := os.MkdirAll(filepath.Dir(m.To), 0o755); err != nil {
+			return fmt.Errorf("creating directory: %w", err)
+		}
+		if err := os.Rename(m.From, m.To); err != nil {
+			return err
+		}
+	} else {
+		moved, err := locales.Read(m.From)
+		if err != nil {
+			return err
+		}
+		kept, err := locales.Read(m.To)
+		if err != nil {
+			return err
+		}
+		for k, v := range moved {
+			if _, ok := kept[k]; !ok {
+				kept[k] = v
+			}
+		}
+		if err := locales.Write(m.To, kept); err != nil {
+			return err
+		}
+		if err := os.Remove(m.From); err != nil {
+			return err
+		}
+	}
+	// Patterns like "{locale}/common.json" leave an empty directory behind;
+	// Remove fails harmlessly when it still holds other files.
+	if dir := filepath.Dir(m.From); dir != filepath.Dir(m.To) {
+		_ = os.Remove(dir)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func TestLocaleFiles_Pattern(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{LocalesDir: dir, LocalePattern: "{locale}/common.json"}
+	require.NoError(t, locales.Write(cfg.LocalePath("fr"), map[string]string{}))
+	require.NoError(t, locales.Write(cfg.LocalePath("pt-BR"), map[string]string{}))
+	require.NoError(t, locales.Write(filepath.Join(dir, "fr", "other.json"), map[string]string{}))
+
+	files, err := cfg.LocaleFiles()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"fr": cfg.LocalePath("fr"), "pt-BR": cfg.LocalePath("pt-BR")}, files)
+}
+
+func TestMigrateLocales(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{LocalesDir: dir}
+	require.NoError(t, locales.Write(cfg.LocalePath("pidgin"), map[string]string{"a": "old A", "b": "B"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("pcm"), map[string]string{"a": "new A"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("french"), map[string]string{"a": "Un"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("test"), map[string]string{}))
+
+	migrations, err := MigrateLocales(cfg, true)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	_, err = os.Stat(cfg.LocalePath("french"))
+	require.NoError(t, err, "dry run must not touch files")
+
+	_, err = MigrateLocales(cfg, false)
+	require.NoError(t, err)
+
+	pcm, err := locales.Read(cfg.LocalePath("pcm"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "new A", "b": "B"}, pcm)
+	fr, err := locales.Read(cfg.LocalePath("fr"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "Un"}, fr)
+	_, err = os.Stat(cfg.LocalePath("pidgin"))
+	assert.True(t, os.IsNotExist(err))
+}
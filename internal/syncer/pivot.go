@@ -0,0 +1,71 @@
+package syncer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/llm"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// Source returns the locale Message.Default is written in, falling back to
+// DefaultSource.
+func (c SyncConfig) Source() string {
+	if c.SourceLocale == "" {
+		return DefaultSource
+	}
+	return c.SourceLocale
+}
+
+// PivotFor returns the locale whose translations locale is translated
+// from, or "" to translate straight from the source.
+func (c SyncConfig) PivotFor(locale string) string {
+	pivot := c.PivotLocale
+	if p := c.LocaleOptions[locale].Pivot; p != "" {
+		pivot = p
+	}
+	if pivot == locale || pivot == c.Source() {
+		return ""
+	}
+	return pivot
+}
+
+// readPivot loads the pivot locale as the runtime would see it: its own
+// translations over those of its ancestors.
+func readPivot(log *logger.Logger, cfg SyncConfig, pivot string) map[string]string {
+	if pivot == "" {
+		return nil
+	}
+	translations := readAncestors(log, cfg, pivot)
+	own, err := locales.Read(cfg.LocalePath(pivot))
+	if err != nil {
+		log.Warn("failed to read pivot locale file", "pivot", pivot, "error", err.Error())
+		return translations
+	}
+	for k, v := range own {
+		translations[k] = v
+	}
+	return translations
+}
+
+func pivotBatch(log *logger.Logger, client ChatClient, batch []Message, lc localeContext, cfg SyncConfig) (map[string]string, error) {
+	build := func(b []Message, lc localeContext) string { return buildPivotPrompt(b, lc, cfg) }
+	return callWithRetries(log, client, batch, build, lc, cfg)
+}
+
+// buildPivotPrompt translates from the pivot locale's existing
+// translations, keeping the source text as context. This helps
+// low-resource targets that models handle better from a related language.
+func buildPivotPrompt(batch []Message, lc localeContext, cfg SyncConfig) string {
+	locale, pivot := lc.locale, lc.pivot
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Translate the \"text\" of these UI strings from %s (%s) into %s (%s) preserving placeholders and maintaining the same tone and context. The original %s (%s) \"source\" is given for context only.\n\n",
+		locales.DisplayName(pivot), pivot, locales.DisplayName(locale), locale, locales.DisplayName(cfg.Source()), cfg.Source()))
+	writeGuidance(&b, batch, locale, cfg)
+	writeReferenceNote(&b, lc)
+	writeData(&b, batch, lc, func(m Message) llm.DataItem {
+		return llm.DataItem{Text: lc.pivotTranslations[m.Key], Source: m.Default}
+	})
+	return b.String()
+}
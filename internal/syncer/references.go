@@ -0,0 +1,126 @@
+package syncer
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/styleguide"
+)
+
+// localeContext carries what is known about the locale being synced
+// beyond the source strings: inherited, pivot and sibling translations.
+type localeContext struct {
+	locale string
+	// sourceLocale is the language of the messages' defaults when it is
+	// not cfg.Source(), as for back-translations.
+	sourceLocale      string
+	parent            string
+	inherited         map[string]string
+	pivot             string
+	pivotTranslations map[string]string
+	// references holds existing translations of the same key in other
+	// locales, at most cfg.MaxReferences per key.
+	references map[string][]reference
+	// guide is the locale's style guide, nil when there is none.
+	guide *styleguide.Guide
+	// budgets holds the length budget of the keys in the current batch.
+	budgets map[string]int
+	// model and temperature override the locale's model and the default
+	// temperature, to generate different candidates for one string.
+	model       string
+	temperature *float32
+	// deterministic is set for a provider that answers the same batch the
+	// same way every time, so asking again cannot fix a translation.
+	deterministic bool
+	// summary collects which model produced each translation; nil when
+	// nothing is being counted.
+	summary *localeSummary
+}
+
+// reference is a translation of a key into another locale, shown to the
+// model to disambiguate short or context-free source strings.
+type reference struct {
+	locale string
+	text   string
+}
+
+// referenceCandidates returns the locales whose files may be quoted as
+// references for locale: ReferenceLocales when set, otherwise every
+// locale file on disk, in a stable order.
+func referenceCandidates(cfg SyncConfig, locale string) ([]string, error) {
+	names := cfg.ReferenceLocales
+	if len(names) == 0 {
+		files, err := cfg.LocaleFiles()
+		if err != nil {
+			return nil, err
+		}
+		names = slices.Sorted(maps.Keys(files))
+	}
+	var out []string
+	for _, name := range names {
+		canonical, err := locales.Canonicalize(name)
+		if err != nil || canonical == locale || canonical == cfg.Source() || slices.Contains(out, canonical) {
+			continue
+		}
+		out = append(out, canonical)
+	}
+	return out, nil
+}
+
+// loadReferences reads the translations other locales already have for
+// the missing messages. The parent and pivot are skipped, their
+// translations are in the prompt already. Unreadable files are skipped.
+func loadReferences(log *logger.Logger, cfg SyncConfig, lc localeContext, missing []Message) map[string][]reference {
+	if cfg.MaxReferences <= 0 || len(missing) == 0 {
+		return nil
+	}
+	wanted := make(map[string]bool, len(missing))
+	for _, m := range missing {
+		wanted[m.Key] = true
+	}
+	candidates, err := referenceCandidates(cfg, lc.locale)
+	if err != nil {
+		log.Warn("failed to list reference locales", "locale", lc.locale, "error", err.Error())
+		return nil
+	}
+
+	refs := make(map[string][]reference)
+	used := 0
+	// Candidates are tried in order, so the cap favours the first
+	// locales listed; a key missing in one falls through to the next.
+	for _, other := range candidates {
+		if other == lc.parent || other == lc.pivot {
+			continue
+		}
+		translations, err := locales.Read(cfg.LocalePath(other))
+		if err != nil || len(translations) == 0 {
+			continue
+		}
+		contributed := false
+		for key, text := range translations {
+			if wanted[key] && len(refs[key]) < cfg.MaxReferences && strings.TrimSpace(text) != "" {
+				refs[key] = append(refs[key], reference{locale: other, text: text})
+				contributed = true
+			}
+		}
+		if contributed {
+			used++
+		}
+	}
+	if len(refs) > 0 {
+		log.Info("using translations from other locales as references", "locale", lc.locale, "locales", used, "keys", len(refs))
+	}
+	return refs
+}
+
+// writeReferenceNote explains the "references" field of data items when
+// any are present.
+func writeReferenceNote(b *strings.Builder, lc localeContext) {
+	if len(lc.references) == 0 {
+		return
+	}
+	b.WriteString("Some items have \"references\": existing translations in other languages, keyed by locale. Use them only to understand the intended meaning of ambiguous strings; do not copy them.\n\n")
+}
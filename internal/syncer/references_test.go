@@ -0,0 +1,53 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func TestSyncLocale_QuotesReferenceTranslations(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{BatchSize: 10, MaxRetries: 1, LocalesDir: dir, MaxReferences: 2}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), map[string]string{"post": "Veröffentlichen"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("es"), map[string]string{"post": "Publicar"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("fr"), map[string]string{"post": "Publier"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("en"), map[string]string{"post": "Post"}))
+
+	client := &recordingClient{reply: func(string) string { return `post: "Publicar"` }}
+	cfg.Client = client
+	msgs := []Message{{Key: "post", Default: "Post"}}
+
+	_, err := syncLocale(logger.New(), msgs, "pt", cfg)
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 1)
+	assert.Contains(t, client.prompts[0], "only to understand the intended meaning")
+	assert.Contains(t, client.prompts[0], `"post": {"text":"Post","references":{"de":"Veröffentlichen","es":"Publicar"}}`)
+	assert.NotContains(t, client.prompts[0], "Publier", "capped at MaxReferences locales")
+	assert.NotContains(t, client.prompts[0], `en: "Post"`, "the source locale is never a reference")
+}
+
+func TestLoadReferences(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{LocalesDir: dir, MaxReferences: 1, ReferenceLocales: []string{"fr", "de"}}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), map[string]string{"a": "A-de", "b": "B-de", "c": "C-de"}))
+	require.NoError(t, locales.Write(cfg.LocalePath("fr"), map[string]string{"a": "A-fr"}))
+
+	missing := []Message{{Key: "a"}, {Key: "b"}}
+
+	refs := loadReferences(logger.New(), cfg, localeContext{locale: "it"}, missing)
+	assert.Equal(t, []reference{{locale: "fr", text: "A-fr"}}, refs["a"])
+	assert.Equal(t, []reference{{locale: "de", text: "B-de"}}, refs["b"], "a key missing in one locale falls through to the next")
+	assert.NotContains(t, refs, "c", "only keys being translated are kept")
+	assert.Nil(t, loadReferences(logger.New(), cfg, localeContext{locale: "it"}, nil))
+
+	cfg.MaxReferences = 0
+	assert.Nil(t, loadReferences(logger.New(), cfg, localeContext{locale: "it"}, missing))
+
+	cfg.MaxReferences = 2
+	refs = loadReferences(logger.New(), cfg, localeContext{locale: "it", pivot: "fr"}, missing)
+	assert.Equal(t, []reference{{locale: "de", text: "A-de"}}, refs["a"], "the pivot is already in the prompt")
+}
@@ -3,12 +3,15 @@ package syncer
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/llm"
 )
 
 // failOnceClient fails the first call then succeeds.
@@ -33,7 +36,7 @@ func TestTranslateBatch_RetriesAndSucceeds(t *testing.T) {
 
 	cfg := SyncConfig{MaxRetries: 2, OpenAIModel: "test"}
 
-	translations, err := translateBatch(log, client, batch, "en", cfg)
+	translations, err := translateBatch(log, client, batch, localeContext{locale: "en"}, cfg)
 	require.NoError(t, err)
 	assert.Equal(t, map[string]string{"key": "Translated"}, translations)
 	assert.True(t, client.called)
@@ -52,6 +55,73 @@ func TestTranslateBatch_FailsAfterRetries(t *testing.T) {
 	batch := []Message{{Key: "k", Default: "T"}}
 	cfg := SyncConfig{MaxRetries: 1, OpenAIModel: "test"}
 
-	_, err := translateBatch(log, client, batch, "en", cfg)
+	_, err := translateBatch(log, client, batch, localeContext{locale: "en"}, cfg)
 	require.Error(t, err)
 }
+
+func TestTranslateBatch_MasksTagsAndArguments(t *testing.T) {
+	client := &recordingClient{reply: func(prompt string) string {
+		if strings.Contains(prompt, "had problems") {
+			return `terms: "Lies die ⟦0⟧AGB⟦1⟧, ⟦2⟧"`
+		}
+		return `terms: "Lies die ⟦0⟧AGB, ⟦2⟧"`
+	}}
+	batch := []Message{{Key: "terms", Default: "Read the <Link>terms</Link>, {name}"}}
+	cfg := SyncConfig{MaxRetries: 2, OpenAIModel: "test"}
+
+	got, err := translateBatch(logger.New(), client, batch, localeContext{locale: "de"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"terms": "Lies die <Link>AGB</Link>, {name}"}, got)
+
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], `"terms": {"text":"Read the ⟦0⟧terms⟦1⟧, ⟦2⟧"}`)
+	assert.NotContains(t, client.prompts[0], "<Link>")
+	assert.Contains(t, client.systems[0], "Copy every token exactly once")
+	assert.Contains(t, client.prompts[1], `"terms": "[tokens] token ⟦1⟧ is missing"`)
+}
+
+func TestTranslateBatch_RetriesWrongLanguage(t *testing.T) {
+	client := &recordingClient{reply: func(prompt string) string {
+		if strings.Contains(prompt, "[language]") {
+			return `{"greeting": "Это не ваш аккаунт", "brand": "Powered by nogodey"}`
+		}
+		return `{"greeting": "Це не ваш обліковий запис", "brand": "Powered by nogodey"}`
+	}}
+	batch := []Message{{Key: "greeting", Default: "This is not your account"}, {Key: "brand", Default: "Powered by nogodey"}}
+	cfg := SyncConfig{MaxRetries: 2, OpenAIModel: "test", SourceLocale: "en", AllowIdentical: []string{"brand"}}
+
+	got, err := translateBatch(logger.New(), client, batch, localeContext{locale: "ru"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"greeting": "Это не ваш аккаунт", "brand": "Powered by nogodey"}, got)
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[1], `"[language] looks like Ukrainian rather than Russian"`)
+
+	// With the check disabled the first answer is kept.
+	cfg.DisabledChecks = []string{LanguageCheck}
+	got, err = translateBatch(logger.New(), client, batch, localeContext{locale: "ru"}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "Це не ваш обліковий запис", got["greeting"])
+}
+
+// fatalClient fails with an error retrying cannot fix and notes the
+// locales of the request.
+type fatalClient struct {
+	calls   int
+	locales [2]string
+}
+
+func (f *fatalClient) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.calls++
+	f.locales[0], f.locales[1] = llm.Locales(ctx)
+	return openai.ChatCompletionResponse{}, fmt.Errorf("unsupported language pair: %w", llm.ErrFatal)
+}
+
+func TestTranslateBatch_FatalErrorsAreNotRetried(t *testing.T) {
+	client := &fatalClient{}
+	cfg := SyncConfig{SourceLocale: "en", MaxRetries: 3, OpenAIModel: "test"}
+
+	_, err := translateBatch(logger.New(), client, []Message{{Key: "k", Default: "T"}}, localeContext{locale: "de"}, cfg)
+	require.ErrorIs(t, err, llm.ErrFatal)
+	assert.Equal(t, 1, client.calls)
+	assert.Equal(t, [2]string{"en", "de"}, client.locales)
+}
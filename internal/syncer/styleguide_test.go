@@ -0,0 +1,51 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func TestSyncLocale_SendsStyleGuide(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{BatchSize: 10, MaxRetries: 1, LocalesDir: dir, StyleGuideDir: filepath.Join(dir, "style")}
+	require.NoError(t, os.MkdirAll(cfg.StyleGuideDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.StyleGuideDir, "de.yaml"), []byte("formality: always du\nforbidden: [Sie]\n"), 0o644))
+
+	client := &recordingClient{reply: func(string) string { return `save: "Speichern Sie"` }}
+	cfg.Client = client
+	_, err := syncLocale(logger.New(), []Message{{Key: "save", Default: "Save"}}, "de", cfg)
+	require.NoError(t, err)
+
+	require.Len(t, client.systems, 1)
+	assert.Contains(t, client.systems[0], "Style guide for German:")
+	assert.Contains(t, client.systems[0], "- Formality: always du")
+	assert.Contains(t, client.systems[0], `- Never use: "Sie"`)
+
+	// Locales without a guide get the plain system prompt.
+	client.systems = nil
+	_, err = syncLocale(logger.New(), []Message{{Key: "save", Default: "Save"}}, "fr", cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, client.systems[0], "Style guide")
+}
+
+func TestSyncLocale_ExplicitStyleGuideMustExist(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		BatchSize: 10, MaxRetries: 1, LocalesDir: dir,
+		LocaleOptions: map[string]config.LocaleOptions{"de": {StyleGuide: filepath.Join(dir, "missing.yaml")}},
+		Client:        &recordingClient{reply: func(string) string { return `save: "Speichern"` }},
+	}
+	_, err := syncLocale(logger.New(), []Message{{Key: "save", Default: "Save"}}, "de", cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading style guide for de")
+
+	_, err = locales.Read(cfg.LocalePath("de"))
+	assert.Error(t, err, "nothing is written when the guide cannot be loaded")
+}
@@ -1,15 +1,18 @@
 package syncer
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/you/nogodey/cmd/nogodey/logger"
 	"github.com/you/nogodey/internal/locales"
 	"github.com/you/nogodey/internal/messages"
+	"github.com/you/nogodey/internal/pseudo"
 )
 
 func TestSyncCommand_NoAPIKey(t *testing.T) {
@@ -32,7 +35,7 @@ func TestSyncLocale_NoMissingKeys(t *testing.T) {
 	t.Cleanup(func() { _ = os.Chdir(cwd) })
 	require.NoError(t, os.Chdir(tmp))
 
-	err := syncLocale(log, messages, "en", SyncConfig{BatchSize: 5, MaxRetries: 1})
+	_, err := syncLocale(log, messages, "en", SyncConfig{BatchSize: 5, MaxRetries: 1})
 	assert.NoError(t, err)
 }
 
@@ -57,3 +60,98 @@ func TestWriteLocaleJSON_DirError(t *testing.T) {
 	err := locales.Write(filepath.Join(collision, "en.json"), map[string]string{"a": "b"})
 	require.Error(t, err)
 }
+
+func TestSyncCommand_RefusesAmbiguousKeys(t *testing.T) {
+	tmp := t.TempDir()
+	dist := filepath.Join(tmp, "js", "dist")
+	require.NoError(t, os.MkdirAll(dist, 0o755))
+	manifest := `[{"key":"home-hello","default":"Hello!","file":"home.tsx"},{"key":"home-hello","default":"Hello?","file":"home.tsx"}]`
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "messages.json"), []byte(manifest), 0o644))
+
+	cwd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	require.NoError(t, os.Chdir(tmp))
+
+	cfg := SyncConfig{Locales: []string{"en"}, BatchSize: 10, MaxRetries: 1, OpenAIKey: "test", OpenAIModel: "gpt", Client: alwaysFailClient{}}
+	err := SyncCommand(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ambiguous")
+	_, statErr := os.Stat(filepath.Join(tmp, "js", "locales", "en.json"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestSyncConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "messages.json")
+	require.NoError(t, os.WriteFile(manifest, []byte("[]"), 0o644))
+
+	valid := SyncConfig{Locales: []string{"pidgin", "pt-BR"}, BatchSize: 10, MaxRetries: 1, OpenAIKey: "k", OpenAIModel: "gpt-4", ManifestPath: manifest}
+	require.NoError(t, valid.Validate())
+
+	bad := SyncConfig{
+		Locales:       []string{"fr", "fr", "../etc"},
+		BatchSize:     0,
+		MaxRetries:    -1,
+		OpenAIModel:   "gpt 4",
+		ManifestPath:  filepath.Join(dir, "missing.json"),
+		LocalePattern: "common.json",
+		Sources:       map[string]string{"batch_size": "env SYNC_BATCH_SIZE"},
+	}
+	err := bad.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		"batch size must be at least 1, got 0 (from env SYNC_BATCH_SIZE)",
+		"max retries must be at least 1",
+		`locale "fr" listed twice`,
+		`invalid locale "../etc"`,
+		"OPENAI_API_KEY",
+		"must not contain whitespace",
+		"missing.json not found",
+		"must contain {locale}",
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestSyncLocale_PseudoProvider(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{SourceLocale: "en", BatchSize: 10, MaxRetries: 1, Provider: "pseudo", PseudoRatio: 1, OpenAIModel: "gpt", LocalesDir: dir}
+	assert.NotContains(t, cfg.Validate().Error(), "OPENAI_API_KEY", "no API key is needed")
+	msgs := []Message{
+		{Key: "delete", Default: "Delete {name}?"},
+		{Key: "files", Default: "{count, plural, one {<b>#</b> file} other {<b>#</b> files}}"},
+	}
+
+	_, err := syncLocale(logger.New(), msgs, "en-XA", cfg)
+	require.NoError(t, err)
+	got, err := locales.Read(cfg.LocalePath("en-XA"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"delete": "[Ðéļéţé {name}]?",
+		"files":  "[{count, plural, one {<b>#</b> ƒîļé} other {<b>#</b> ƒîļéš}}]",
+	}, got)
+}
+
+// countingClient counts the calls passed on to next.
+type countingClient struct {
+	next  ChatClient
+	calls int
+}
+
+func (c *countingClient) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	c.calls++
+	return c.next.CreateChatCompletion(ctx, req)
+}
+
+func TestSyncLocale_PseudoOverBudgetIsNotRetried(t *testing.T) {
+	client := &countingClient{next: pseudo.Client{Ratio: pseudo.DefaultRatio}}
+	dir := t.TempDir()
+	cfg := SyncConfig{SourceLocale: "en", BatchSize: 10, MaxRetries: 3, Provider: "pseudo", OpenAIModel: "gpt", LocalesDir: dir, LengthRatios: DefaultLengthRatios, Client: client}
+
+	_, err := syncLocale(logger.New(), []Message{{Key: "ok", Default: "OK", Kind: "title"}}, "en-XA", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.calls, "the same answer would come back")
+	got, err := locales.Read(cfg.LocalePath("en-XA"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ok": "[ÖĶ one]"}, got, "kept over budget")
+}
@@ -29,7 +29,7 @@ func TestSyncIntegration(t *testing.T) {
 
 	localePath := filepath.Join(tmp, "js", "locales")
 	require.NoError(t, os.MkdirAll(localePath, 0o755))
-	require.NoError(t, os.WriteFile(filepath.Join(localePath, "en.json"), []byte(`{"a":"Text A"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(localePath, "pcm.json"), []byte(`{"a":"Tekst A"}`), 0o644))
 
 	stubResp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "b: \"Translated B\""}}}}
 	client := stubClient{resp: stubResp}
@@ -38,13 +38,52 @@ func TestSyncIntegration(t *testing.T) {
 	t.Cleanup(func() { _ = os.Chdir(cwd) })
 	require.NoError(t, os.Chdir(tmp))
 
-	cfg := SyncConfig{Locales: []string{"en"}, BatchSize: 10, MaxRetries: 1, OpenAIKey: "test", OpenAIModel: "gpt-test", Client: client}
+	cfg := SyncConfig{Locales: []string{"pcm"}, BatchSize: 10, MaxRetries: 1, OpenAIKey: "test", OpenAIModel: "gpt-test", Client: client}
 
 	err := SyncCommand(cfg)
 	require.NoError(t, err)
 
-	updatedData, _ := os.ReadFile(filepath.Join(localePath, "en.json"))
+	updatedData, _ := os.ReadFile(filepath.Join(localePath, "pcm.json"))
 	var got map[string]string
 	require.NoError(t, json.Unmarshal(updatedData, &got))
-	assert.Equal(t, map[string]string{"a": "Text A", "b": "Translated B"}, got)
+	assert.Equal(t, map[string]string{"a": "Tekst A", "b": "Translated B"}, got)
+}
+
+func TestSyncIntegration_CustomPaths(t *testing.T) {
+	tmp := t.TempDir()
+	manifest := filepath.Join(tmp, "build", "messages.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(manifest), 0o755))
+	data, _ := json.Marshal([]Message{{Key: "a", Default: "Text A"}})
+	require.NoError(t, os.WriteFile(manifest, data, 0o644))
+
+	stubResp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "a: \"Texte A\""}}}}
+	cfg := SyncConfig{
+		Locales: []string{"fr"}, BatchSize: 10, MaxRetries: 1, OpenAIKey: "test", OpenAIModel: "gpt-test",
+		ManifestPath:  manifest,
+		LocalesDir:    filepath.Join(tmp, "src", "i18n"),
+		LocalePattern: "{locale}/common.json",
+		Client:        stubClient{resp: stubResp},
+	}
+	require.NoError(t, SyncCommand(cfg))
+
+	got, err := os.ReadFile(filepath.Join(tmp, "src", "i18n", "fr", "common.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":"Texte A"}`, string(got))
+}
+
+func TestSyncIntegration_SourceLocaleIsCopied(t *testing.T) {
+	tmp := t.TempDir()
+	manifest := filepath.Join(tmp, "messages.json")
+	data, _ := json.Marshal([]Message{{Key: "a", Default: "Bonjour"}})
+	require.NoError(t, os.WriteFile(manifest, data, 0o644))
+
+	cfg := SyncConfig{
+		Locales: []string{"fr"}, SourceLocale: "fr", BatchSize: 10, MaxRetries: 1, OpenAIKey: "test", OpenAIModel: "gpt-test",
+		ManifestPath: manifest, LocalesDir: tmp, Client: alwaysFailClient{},
+	}
+	require.NoError(t, SyncCommand(cfg))
+
+	got, err := os.ReadFile(filepath.Join(tmp, "fr.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":"Bonjour"}`, string(got))
 }
@@ -1,18 +1,27 @@
 package syncer
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"math"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/checks"
+	"github.com/you/nogodey/internal/command"
+	"github.com/you/nogodey/internal/config"
 	"github.com/you/nogodey/internal/llm"
 	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/mask"
 	"github.com/you/nogodey/internal/messages"
+	"github.com/you/nogodey/internal/pseudo"
+	"github.com/you/nogodey/internal/styleguide"
 )
 
 // Message is an alias for messages.Message
@@ -21,58 +30,6 @@ type Message = messages.Message
 // ChatClient alias for llm.ChatClient.
 type ChatClient = llm.ChatClient
 
-// SyncConfig holds configuration for the sync command.
-type SyncConfig struct {
-	Locales     []string
-	BatchSize   int
-	MaxRetries  int
-	OpenAIKey   string
-	OpenAIModel string
-	Client      llm.ChatClient // allows tests to inject a stub
-}
-
-// loadEnvConfig loads environment variables from a .env file if present.
-func loadEnvConfig() { _ = godotenv.Load() }
-
-// GetEnvConfig builds a SyncConfig from CLI flags + environment variables.
-func GetEnvConfig(flagLocales []string, flagBatchSize, flagMaxRetries int) SyncConfig {
-	loadEnvConfig()
-
-	cfg := SyncConfig{
-		Locales:     flagLocales,
-		BatchSize:   flagBatchSize,
-		MaxRetries:  flagMaxRetries,
-		OpenAIKey:   os.Getenv("OPENAI_API_KEY"),
-		OpenAIModel: getEnvWithDefault("OPENAI_MODEL", "gpt-3.5-turbo"),
-	}
-
-	if v := os.Getenv("SYNC_BATCH_SIZE"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			cfg.BatchSize = n
-		}
-	}
-	if v := os.Getenv("SYNC_MAX_RETRIES"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			cfg.MaxRetries = n
-		}
-	}
-	if envLocales := os.Getenv("SYNC_DEFAULT_LOCALES"); envLocales != "" && len(flagLocales) == 1 && flagLocales[0] == "pidgin" {
-		parts := strings.Split(envLocales, ",")
-		for i, p := range parts {
-			parts[i] = strings.TrimSpace(p)
-		}
-		cfg.Locales = parts
-	}
-	return cfg
-}
-
-func getEnvWithDefault(key, def string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return def
-}
-
 // SyncCommand is the public entry used by the CLI.
 func SyncCommand(cfg SyncConfig) error {
 	log := logger.New()
@@ -81,105 +38,423 @@ func SyncCommand(cfg SyncConfig) error {
 
 	log.Info("starting sync process", "locales", cfg.Locales, "batch_size", cfg.BatchSize, "model", cfg.OpenAIModel, "has_api_key", cfg.OpenAIKey != "")
 
-	if cfg.OpenAIKey == "" {
-		log.Error("OpenAI API key not found", "help", "Set OPENAI_API_KEY environment variable or create a .env file")
-		return fmt.Errorf("OPENAI_API_KEY not found in environment variables or .env file")
+	if err := cfg.Validate(); err != nil {
+		log.Error("invalid sync configuration", "error", err.Error(), "help", "Run 'nogodey config print' to see where each setting comes from")
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
-	messagesSlice, err := messages.Read("js/dist/messages.json")
+	manifest := cfg.Manifest()
+	messagesSlice, err := messages.Read(manifest)
 	if err != nil {
-		log.Error("failed to read messages.json", "error", err.Error())
-		return fmt.Errorf("reading messages.json: %w", err)
+		log.Error("failed to read manifest", "path", manifest, "error", err.Error())
+		return fmt.Errorf("reading %s: %w", manifest, err)
 	}
 	log.Info("loaded messages", "count", len(messagesSlice))
 
-	for _, locale := range cfg.Locales {
-		if err := syncLocale(log, messagesSlice, locale, cfg); err != nil {
+	messagesSlice, collisions, err := messages.Validate(messagesSlice, cfg.CollisionPolicy)
+	for _, c := range collisions {
+		log.Warn("ambiguous message key", "key", c.Key,
+			"first", c.First.Default, "first_at", c.First.Position(),
+			"second", c.Second.Default, "second_at", c.Second.Position())
+	}
+	if err != nil {
+		log.Error("manifest validation failed", "collisions", len(collisions), "help", "Rename one of the strings or rerun with --on-collision skip|first")
+		return fmt.Errorf("validating %s: %w", manifest, err)
+	}
+	if len(collisions) > 0 {
+		log.Warn("resolved ambiguous keys", "policy", cfg.CollisionPolicy, "collisions", len(collisions), "messages", len(messagesSlice))
+	}
+
+	cfg.spent = make(usage)
+	var summaries []*localeSummary
+	for _, locale := range orderByDependency(cfg.Locales, cfg) {
+		summary, err := syncLocale(log, messagesSlice, locale, cfg)
+		if err != nil {
 			log.Error("failed to sync locale", "locale", locale, "error", err.Error())
 			return fmt.Errorf("syncing locale %s: %w", locale, err)
 		}
+		summaries = append(summaries, summary)
 	}
+	logSummary(log, cfg, summaries)
 
 	log.Info("sync process completed successfully")
 	return nil
 }
 
-func syncLocale(log *logger.Logger, messages []Message, locale string, cfg SyncConfig) error {
+func syncLocale(log *logger.Logger, messages []Message, locale string, cfg SyncConfig) (*localeSummary, error) {
 	timer := logger.StartTimer("sync_locale_" + locale)
 	defer timer.ObserveWithLogger(log)
 
 	log.Info("syncing locale", "locale", locale)
 
-	localeFile := fmt.Sprintf("js/locales/%s.json", locale)
+	localeFile := cfg.LocalePath(locale)
 	existingTranslations, err := locales.Read(localeFile)
 	if err != nil {
 		log.Warn("failed to read existing locale file, starting fresh", "locale", locale, "error", err.Error())
 		existingTranslations = make(map[string]string)
 	}
-	log.Info("loaded existing translations", "locale", locale, "count", len(existingTranslations))
+	log.Info("loaded existing translations", "locale", locale, "path", localeFile, "count", len(existingTranslations))
+
+	lc := localeContext{locale: locale, parent: cfg.ParentOf(locale), inherited: readAncestors(log, cfg, locale), summary: newLocaleSummary(locale)}
+	inherited := lc.inherited
 
 	missing := diffKeys(messages, existingTranslations)
+	if locale == cfg.Source() && len(missing) > 0 {
+		// The source locale is the manifest itself; nothing to translate.
+		for _, m := range missing {
+			existingTranslations[m.Key] = m.Default
+		}
+		log.Info("copied source strings into source locale", "locale", locale, "count", len(missing))
+		if err := locales.Write(localeFile, existingTranslations); err != nil {
+			return nil, fmt.Errorf("writing locale file %s: %w", localeFile, err)
+		}
+		missing = nil
+	}
 	if len(missing) == 0 {
 		log.Info("no missing keys for locale", "locale", locale)
-		return nil
+		return lc.summary, writeMerged(log, messages, locale, existingTranslations, inherited, cfg)
 	}
 	log.Info("found missing keys", "locale", locale, "count", len(missing))
 
-	var client ChatClient
-	if cfg.Client != nil {
-		client = cfg.Client
-	} else {
-		client = llm.NewClient(cfg.OpenAIKey)
+	if lc.guide, err = loadStyleGuide(log, cfg, locale); err != nil {
+		return nil, err
+	}
+	post, err := cfg.PostProcessor(locale)
+	if err != nil {
+		return nil, err
 	}
 
-	for i := 0; i < len(missing); i += cfg.BatchSize {
-		end := i + cfg.BatchSize
-		if end > len(missing) {
-			end = len(missing)
-		}
-		batch := missing[i:end]
-		batchNum := (i / cfg.BatchSize) + 1
-		totalBatches := int(math.Ceil(float64(len(missing)) / float64(cfg.BatchSize)))
+	if cfg.spent == nil {
+		cfg.spent = make(usage)
+	}
+	client := ChatClient(meter{newClient(cfg, cfg.Provider), []usage{lc.summary.usage, cfg.spent}})
 
-		log.Info("processing batch", "locale", locale, "batch", batchNum, "total_batches", totalBatches, "keys_in_batch", len(batch))
+	// Keys the parent already translated are only adapted to the regional
+	// variant; keys the pivot locale has are translated from the pivot; the
+	// rest are translated from the source.
+	adapt, fresh := splitInherited(missing, inherited)
+	if len(adapt) > 0 {
+		log.Info("adapting keys from parent locale", "locale", locale, "parent", lc.parent, "count", len(adapt))
+	}
+	lc.pivot = cfg.PivotFor(locale)
+	lc.pivotTranslations = readPivot(log, cfg, lc.pivot)
+	viaPivot, fresh := splitInherited(fresh, lc.pivotTranslations)
+	if lc.pivot != "" {
+		log.Info("translating via pivot locale", "locale", locale, "pivot", lc.pivot, "count", len(viaPivot), "without_pivot", len(fresh))
+	}
+	lc.references = loadReferences(log, cfg, lc, missing)
+	jobs := []struct {
+		msgs []Message
+		run  func([]Message, localeContext) (map[string]string, error)
+	}{
+		{adapt, func(batch []Message, lc localeContext) (map[string]string, error) {
+			return adaptBatch(log, client, batch, lc, cfg)
+		}},
+		{viaPivot, func(batch []Message, lc localeContext) (map[string]string, error) {
+			return pivotBatch(log, client, batch, lc, cfg)
+		}},
+		{fresh, func(batch []Message, lc localeContext) (map[string]string, error) {
+			return translateBatch(log, client, batch, lc, cfg)
+		}},
+	}
+	alternates := make(map[string]Alternates)
 
-		translations, err := translateBatch(log, client, batch, locale, cfg)
-		if err != nil {
-			return fmt.Errorf("translating batch %d for locale %s: %w", batchNum, locale, err)
-		}
+	scheduled := 0
+jobs:
+	for _, job := range jobs {
+		for i := 0; i < len(job.msgs); i += cfg.BatchSize {
+			// A spending limit stops new batches; what was translated so
+			// far is still written.
+			if limit := cfg.overSpent(); limit != "" {
+				lc.summary.skipped = len(missing) - scheduled
+				log.Warn("spending limit reached, not starting new batches", "locale", locale, "limit", limit, "skipped_keys", lc.summary.skipped)
+				break jobs
+			}
+			end := i + cfg.BatchSize
+			if end > len(job.msgs) {
+				end = len(job.msgs)
+			}
+			batch := job.msgs[i:end]
+			scheduled += len(batch)
+			batchNum := (i / cfg.BatchSize) + 1
+			totalBatches := int(math.Ceil(float64(len(job.msgs)) / float64(cfg.BatchSize)))
+
+			log.Info("processing batch", "locale", locale, "batch", batchNum, "total_batches", totalBatches, "keys_in_batch", len(batch))
+
+			// High-visibility keys are translated several times and the
+			// best candidate is kept.
+			normal, special := splitCandidates(batch, cfg)
+			translations := make(map[string]string, len(batch))
+			if len(normal) > 0 {
+				if translations, err = job.run(normal, lc); err != nil {
+					return nil, fmt.Errorf("translating batch %d for locale %s: %w", batchNum, locale, err)
+				}
+			}
+			if len(special) > 0 {
+				chosen, alts, err := generateCandidates(log, client, special, job.run, lc, cfg)
+				if err != nil {
+					return nil, fmt.Errorf("generating candidates in batch %d for locale %s: %w", batchNum, locale, err)
+				}
+				maps.Copy(translations, chosen)
+				for k, a := range alts {
+					for i := range a.Candidates {
+						a.Candidates[i].Text, _ = applyPost(post, a.Candidates[i].Text)
+					}
+					alternates[k] = a
+				}
+			}
 
-		for k, v := range translations {
-			existingTranslations[k] = v
+			for k, v := range translations {
+				if v, err = applyPost(post, v); err != nil {
+					log.Warn("post-processing skipped", "locale", locale, "key", k, "error", err.Error())
+				}
+				if words := lc.guide.Violations(v); len(words) > 0 {
+					log.Warn("translation uses forbidden words", "locale", locale, "key", k, "words", strings.Join(words, ", "), "style_guide", lc.guide.Path)
+				}
+				existingTranslations[k] = v
+			}
+			log.Info("batch completed", "locale", locale, "batch", batchNum, "translations_added", len(translations))
 		}
-		log.Info("batch completed", "locale", locale, "batch", batchNum, "translations_added", len(translations))
 	}
 
 	if err := locales.Write(localeFile, existingTranslations); err != nil {
-		return fmt.Errorf("writing locale file %s: %w", localeFile, err)
+		return nil, fmt.Errorf("writing locale file %s: %w", localeFile, err)
+	}
+	if err := writeAlternates(cfg, locale, alternates); err != nil {
+		return nil, err
+	}
+	if err := writeModels(cfg, lc.summary); err != nil {
+		return nil, err
 	}
 	log.Info("locale sync completed", "locale", locale, "total_keys", len(existingTranslations))
-	return nil
+	return lc.summary, writeMerged(log, messages, locale, existingTranslations, inherited, cfg)
+}
+
+// newClient returns the client injected by tests or one for provider,
+// recorded or replayed through the cassette when one is loaded. The pseudo
+// provider is offline and never goes through the cassette.
+func newClient(cfg SyncConfig, provider string) ChatClient {
+	if cfg.Client != nil {
+		return cfg.Client
+	}
+	if provider == "pseudo" {
+		return pseudo.Client{Ratio: cfg.PseudoRatio}
+	}
+	if cfg.cassette != nil && cfg.cassette.Mode == llm.ModeReplay {
+		return cfg.cassette.Client(nil)
+	}
+	var client ChatClient = llm.NewClient(cfg.OpenAIKey)
+	if provider == "exec" {
+		client = command.Client{Command: cfg.ExecCommand}
+	}
+	if cfg.cassette != nil {
+		return cfg.cassette.Client(client)
+	}
+	return client
 }
 
-func translateBatch(log *logger.Logger, client ChatClient, batch []Message, locale string, cfg SyncConfig) (map[string]string, error) {
-	prompt := buildTranslationPrompt(batch, locale)
+func translateBatch(log *logger.Logger, client ChatClient, batch []Message, lc localeContext, cfg SyncConfig) (map[string]string, error) {
+	build := func(b []Message, lc localeContext) string { return buildTranslationPrompt(b, lc, cfg) }
+	return callWithRetries(log, client, batch, build, lc, cfg)
+}
+
+// loadStyleGuide reads the style guide for locale. A missing file is only
+// an error when the project configured it explicitly.
+func loadStyleGuide(log *logger.Logger, cfg SyncConfig, locale string) (*styleguide.Guide, error) {
+	path, explicit := cfg.StyleGuidePath(locale)
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(path); !explicit && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	guide, err := styleguide.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading style guide for %s: %w", locale, err)
+	}
+	log.Info("loaded style guide", "locale", locale, "path", path)
+	return guide, nil
+}
+
+// callModel sends the prompt built for batch to lc.model, or the locale's
+// model, retrying with exponential backoff up to cfg.MaxRetries attempts. Tags
+// and ICU arguments are masked before build sees the batch, and the
+// locale's style guide goes into the system prompt. Translations with
+// broken tokens or failing checks are requested again, with the problems
+// listed; keys still failing after the last attempt are left untranslated.
+func callModel(log *logger.Logger, client ChatClient, batch []Message, build func([]Message, localeContext) string, lc localeContext, cfg SyncConfig) (map[string]string, error) {
+	locale := lc.locale
+	suite, err := cfg.Checks()
+	if err != nil {
+		return nil, err
+	}
+	detector := cfg.Detector(locale)
+	// A deterministic provider gives the same answer again, so its first
+	// attempt is also its last: over-budget strings are kept and reported
+	// once instead of being requested again.
+	attempts := cfg.MaxRetries
+	if lc.deterministic {
+		attempts = min(attempts, 1)
+	}
+	accepted := make(map[string]string, len(batch))
+	pending := batch
+	var issues []checks.Issue
 	var lastErr error
-	for attempt := 1; attempt <= cfg.MaxRetries; attempt++ {
-		if attempt > 1 {
+	for attempt := 1; attempt <= attempts && len(pending) > 0; attempt++ {
+		if lastErr != nil {
 			backoff := time.Duration(math.Pow(2, float64(attempt-1))) * time.Second
 			log.Info("retrying translation", "locale", locale, "attempt", attempt, "backoff_seconds", backoff.Seconds())
 			time.Sleep(backoff)
 		}
-		translations, err := llm.Call(log, client, prompt, cfg.OpenAIModel)
+		masked, mlc, maskers := maskBatch(pending, lc)
+		mlc.budgets = make(map[string]int, len(pending))
+		for _, m := range pending {
+			mlc.budgets[m.Key] = cfg.Budget(m)
+		}
+		system := lc.guide.Prompt(locales.DisplayName(locale))
+		if slices.ContainsFunc(masked, func(m Message) bool { return maskers[m.Key].Len() > 0 }) {
+			system = strings.TrimSpace(mask.Note + "\n\n" + system)
+		}
+		req := llm.Request{
+			Model:        cfg.ModelFor(locale),
+			Prompt:       build(masked, mlc) + checkFeedback(issues),
+			System:       system,
+			Temperature:  lc.temperature,
+			SourceLocale: cmp.Or(lc.sourceLocale, cfg.Source()),
+			Locale:       locale,
+		}
+		if lc.model != "" {
+			req.Model = lc.model
+		}
+		translations, err := llm.Call(log, client, req)
 		if err != nil {
 			lastErr = err
 			log.Warn("translation attempt failed", "locale", locale, "attempt", attempt, "error", err.Error())
+			if errors.Is(err, llm.ErrFatal) {
+				break
+			}
 			continue
 		}
-		log.Info("translation successful", "locale", locale, "attempt", attempt, "translations_count", len(translations))
-		return translations, nil
+		lastErr = nil
+
+		// A response with keys that were not asked for has been steered
+		// by something in the data, or mixed up the batch; those values
+		// are dropped and keys it left out are requested again.
+		if extra := unexpectedKeys(translations, pending); len(extra) > 0 {
+			log.Warn("response contains unexpected keys, ignoring them", "locale", locale, "attempt", attempt, "keys", strings.Join(extra, ", "))
+		}
+		issues = nil
+		var retry []Message
+		for _, m := range pending {
+			t, ok := translations[m.Key]
+			if !ok {
+				issues = append(issues, checks.Issue{Key: m.Key, Check: "response", Msg: "missing from the response"})
+				retry = append(retry, m)
+				continue
+			}
+			t, err := maskers[m.Key].Unmask(t)
+			if err != nil {
+				msg := strings.ReplaceAll(err.Error(), "\n", "; ")
+				issues = append(issues, checks.Issue{Key: m.Key, Check: "tokens", Msg: msg})
+				retry = append(retry, m)
+				continue
+			}
+			if found := suite.Run(m.Key, m.Default, t); len(found) > 0 {
+				issues = append(issues, found...)
+				retry = append(retry, m)
+				continue
+			}
+			// The language heuristics can be wrong, and a translation that is
+			// too long is still better than none, so the last attempt keeps
+			// such translations and only flags them.
+			if found := detector.Check(m.Key, m.Default, t); len(found) > 0 {
+				if attempt < attempts {
+					for _, msg := range found {
+						issues = append(issues, checks.Issue{Key: m.Key, Check: LanguageCheck, Msg: msg})
+					}
+					retry = append(retry, m)
+					continue
+				}
+				log.Warn("translation may be in the wrong language", "locale", locale, "key", m.Key, "issue", strings.Join(found, "; "), "translation", t)
+			}
+			if over := cfg.overBudget(m, t); over != "" {
+				if attempt < attempts {
+					issues = append(issues, checks.Issue{Key: m.Key, Check: "length", Msg: over})
+					retry = append(retry, m)
+					continue
+				}
+				log.Warn("translation exceeds length budget", "locale", locale, "key", m.Key, "budget", cfg.Budget(m), "translation", t)
+			}
+			accepted[m.Key] = t
+		}
+		log.Info("translation successful", "locale", locale, "attempt", attempt, "translations_count", len(accepted), "failed_checks", len(retry))
+		pending = retry
+	}
+	if lastErr != nil && len(accepted) == 0 {
+		if errors.Is(lastErr, llm.ErrFatal) {
+			return nil, fmt.Errorf("translation failed: %w", lastErr)
+		}
+		return nil, fmt.Errorf("translation failed after %d attempts: %w", attempts, lastErr)
+	}
+	for _, i := range issues {
+		log.Warn("translation failed checks, leaving key untranslated", "locale", locale, "key", i.Key, "check", i.Check, "issue", i.Msg)
+	}
+	return accepted, nil
+}
+
+// maskBatch masks the tags and ICU arguments of every message in batch,
+// and the same tokens in the parent, pivot and reference translations of
+// those keys.
+func maskBatch(batch []Message, lc localeContext) ([]Message, localeContext, map[string]*mask.Masker) {
+	masked := make([]Message, len(batch))
+	maskers := make(map[string]*mask.Masker, len(batch))
+	mlc := lc
+	mlc.inherited = make(map[string]string)
+	mlc.pivotTranslations = make(map[string]string)
+	mlc.references = make(map[string][]reference)
+	for i, m := range batch {
+		mk := mask.New(m.Default)
+		maskers[m.Key] = mk
+		masked[i] = m
+		masked[i].Default = mk.Text
+		if v, ok := lc.inherited[m.Key]; ok {
+			mlc.inherited[m.Key] = mk.Apply(v)
+		}
+		if v, ok := lc.pivotTranslations[m.Key]; ok {
+			mlc.pivotTranslations[m.Key] = mk.Apply(v)
+		}
+		for _, r := range lc.references[m.Key] {
+			mlc.references[m.Key] = append(mlc.references[m.Key], reference{locale: r.locale, text: mk.Apply(r.text)})
+		}
 	}
-	return nil, fmt.Errorf("translation failed after %d attempts: %w", cfg.MaxRetries, lastErr)
+	return masked, mlc, maskers
+}
+
+// unexpectedKeys returns the keys of translations that are not in batch,
+// sorted.
+func unexpectedKeys(translations map[string]string, batch []Message) []string {
+	var extra []string
+	for k := range translations {
+		if !slices.ContainsFunc(batch, func(m Message) bool { return m.Key == k }) {
+			extra = append(extra, k)
+		}
+	}
+	slices.Sort(extra)
+	return extra
+}
+
+// checkFeedback tells the model what was wrong with its previous answer.
+// Problems can quote source text, so they are JSON-encoded like the data.
+func checkFeedback(issues []checks.Issue) string {
+	if len(issues) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nYour previous translations of these strings had problems. Fix them:\n")
+	for _, i := range issues {
+		b.WriteString("- " + encode(i.Key) + ": " + encode("["+i.Check+"] "+i.Msg) + "\n")
+	}
+	return b.String()
 }
 
 func diffKeys(messages []Message, existing map[string]string) []Message {
@@ -192,11 +467,55 @@ func diffKeys(messages []Message, existing map[string]string) []Message {
 	return missing
 }
 
-func buildTranslationPrompt(batch []Message, locale string) string {
+func buildTranslationPrompt(batch []Message, lc localeContext, cfg SyncConfig) string {
+	locale := lc.locale
 	var b strings.Builder
-	b.WriteString(fmt.Sprintf("Translate these UI strings into %s preserving placeholders and maintaining the same tone and context. Return only the translations in the format KEY: \"Translation\":\n\n", locale))
-	for _, m := range batch {
-		b.WriteString(fmt.Sprintf("%s: \"%s\"\n", m.Key, m.Default))
-	}
+	b.WriteString(fmt.Sprintf("Translate the \"text\" of these UI strings from %s (%s) into %s (%s) preserving placeholders and maintaining the same tone and context.\n\n", locales.DisplayName(cfg.Source()), cfg.Source(), locales.DisplayName(locale), locale))
+	writeGuidance(&b, batch, locale, cfg)
+	writeReferenceNote(&b, lc)
+	writeData(&b, batch, lc, func(m Message) llm.DataItem { return llm.DataItem{Text: m.Default} })
 	return b.String()
 }
+
+// writeGuidance adds the per-locale instructions and the glossary terms
+// used by the batch to a prompt.
+func writeGuidance(b *strings.Builder, batch []Message, locale string, cfg SyncConfig) {
+	if opts := cfg.LocaleOptions[locale]; opts.Instructions != "" {
+		b.WriteString(strings.TrimSpace(opts.Instructions) + "\n\n")
+	}
+	if terms := glossaryFor(batch, locale, cfg.Glossary); len(terms) > 0 {
+		b.WriteString("Glossary (use these renderings exactly):\n")
+		for _, t := range terms {
+			b.WriteString("- " + t + "\n")
+		}
+		b.WriteString("\n")
+	}
+}
+
+// glossaryFor returns prompt lines for the glossary terms that appear in
+// the batch, so unrelated entries do not bloat every request.
+func glossaryFor(batch []Message, locale string, glossary []config.GlossaryEntry) []string {
+	var lines []string
+	for _, g := range glossary {
+		term := strings.ToLower(g.Term)
+		used := false
+		for _, m := range batch {
+			if strings.Contains(strings.ToLower(m.Default), term) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			continue
+		}
+		line := fmt.Sprintf("%q → %q", g.Term, g.Term)
+		if t, ok := g.Translations[locale]; ok {
+			line = fmt.Sprintf("%q → %q", g.Term, t)
+		}
+		if g.Note != "" {
+			line += " (" + g.Note + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
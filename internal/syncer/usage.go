@@ -0,0 +1,129 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/you/nogodey/internal/config"
+)
+
+// DefaultPrices are OpenAI list prices in USD per million tokens at the
+// time of writing. Prices change; the project file's pricing overrides
+// them and adds other models.
+var DefaultPrices = map[string]config.Price{
+	"gpt-3.5-turbo": {Input: 0.50, Output: 1.50},
+	"gpt-4-turbo":   {Input: 10.00, Output: 30.00},
+	"gpt-4o":        {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":   {Input: 0.15, Output: 0.60},
+	"gpt-4.1":       {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":  {Input: 0.40, Output: 1.60},
+}
+
+// Tokens counts the prompt and completion tokens of model calls.
+type Tokens struct {
+	Prompt     int
+	Completion int
+}
+
+// Total returns the prompt and completion tokens together.
+func (t Tokens) Total() int { return t.Prompt + t.Completion }
+
+// usage adds up tokens per model.
+type usage map[string]Tokens
+
+func (u usage) add(model string, t Tokens) {
+	sum := u[model]
+	sum.Prompt += t.Prompt
+	sum.Completion += t.Completion
+	u[model] = sum
+}
+
+func (u usage) total() Tokens {
+	var sum Tokens
+	for _, t := range u {
+		sum.Prompt += t.Prompt
+		sum.Completion += t.Completion
+	}
+	return sum
+}
+
+// cost estimates the price of u in USD. Models without a price are
+// returned, sorted, and count as free.
+func (c SyncConfig) cost(u usage) (float64, []string) {
+	var cost float64
+	var unpriced []string
+	for _, model := range slices.Sorted(maps.Keys(u)) {
+		p, ok := c.Pricing[model]
+		if !ok {
+			unpriced = append(unpriced, model)
+			continue
+		}
+		t := u[model]
+		cost += (float64(t.Prompt)*p.Input + float64(t.Completion)*p.Output) / 1e6
+	}
+	return cost, unpriced
+}
+
+// overSpent describes the limit the run has reached, or returns "" while
+// it may start new batches.
+func (c SyncConfig) overSpent() string {
+	if c.MaxTokens > 0 {
+		if used := c.spent.total().Total(); used >= c.MaxTokens {
+			return fmt.Sprintf("used %d of %d tokens", used, c.MaxTokens)
+		}
+	}
+	if c.MaxCost > 0 {
+		if cost, _ := c.cost(c.spent); cost >= c.MaxCost {
+			return fmt.Sprintf("spent $%.2f of $%.2f", cost, c.MaxCost)
+		}
+	}
+	return ""
+}
+
+// models lists the models a sync may call, sorted.
+func (c SyncConfig) models() []string {
+	models := []string{c.OpenAIModel}
+	for _, opts := range c.LocaleOptions {
+		models = append(models, opts.Model)
+	}
+	for _, f := range c.Fallbacks {
+		models = append(models, ParseModelRef(f, c.provider()).Model)
+	}
+	models = append(models, c.Candidates.Models...)
+	if c.Candidates.Judge {
+		models = append(models, c.Judge())
+	}
+	models = slices.DeleteFunc(models, func(m string) bool { return m == "" })
+	slices.Sort(models)
+	return slices.Compact(models)
+}
+
+// meter passes calls on to ChatClient and adds the usage reported with
+// every response to each of usages.
+type meter struct {
+	ChatClient
+	usages []usage
+}
+
+func (m meter) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	resp, err := m.ChatClient.CreateChatCompletion(ctx, req)
+	if err == nil {
+		t := Tokens{Prompt: resp.Usage.PromptTokens, Completion: resp.Usage.CompletionTokens}
+		for _, u := range m.usages {
+			u.add(req.Model, t)
+		}
+	}
+	return resp, err
+}
+
+// clientFor returns a client for provider that is metered like client.
+func clientFor(client ChatClient, cfg SyncConfig, provider string) ChatClient {
+	c := newClient(cfg, provider)
+	if m, ok := client.(meter); ok {
+		return meter{c, m.usages}
+	}
+	return c
+}
@@ -0,0 +1,82 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/locales"
+)
+
+// usageClient translates the one key it is asked for and reports 80
+// prompt and 20 completion tokens per call.
+type usageClient struct {
+	keys  []string
+	calls int
+}
+
+func (u *usageClient) CreateChatCompletion(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	u.calls++
+	prompt := req.Messages[len(req.Messages)-1].Content
+	reply := "{}"
+	for _, k := range u.keys {
+		if strings.Contains(prompt, `"`+k+`"`) {
+			reply = fmt.Sprintf(`{%q: "Übersetzt"}`, k)
+		}
+	}
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: reply}}},
+		Usage:   openai.Usage{PromptTokens: 80, CompletionTokens: 20, TotalTokens: 100},
+	}, nil
+}
+
+func TestCost(t *testing.T) {
+	cfg := SyncConfig{Pricing: map[string]config.Price{"gpt-4o": {Input: 2.5, Output: 10}}}
+	u := usage{"gpt-4o": {Prompt: 1_000_000, Completion: 100_000}, "local": {Prompt: 5}}
+	cost, unpriced := cfg.cost(u)
+	assert.InDelta(t, 3.5, cost, 1e-9)
+	assert.Equal(t, []string{"local"}, unpriced)
+	assert.Equal(t, Tokens{Prompt: 1_000_005, Completion: 100_000}, u.total())
+}
+
+func TestSyncLocale_StopsAtTokenLimit(t *testing.T) {
+	dir := t.TempDir()
+	client := &usageClient{keys: []string{"one", "two", "three"}}
+	cfg := SyncConfig{
+		SourceLocale: "en",
+		BatchSize:    1,
+		MaxRetries:   1,
+		MaxTokens:    150,
+		OpenAIModel:  "gpt-4o",
+		Pricing:      DefaultPrices,
+		LocalesDir:   dir,
+		Client:       client,
+	}
+	msgs := []Message{{Key: "one", Default: "One"}, {Key: "two", Default: "Two"}, {Key: "three", Default: "Three"}}
+
+	summary, err := syncLocale(logger.New(), msgs, "de", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls, "the limit is checked before each batch")
+	assert.Equal(t, 1, summary.skipped)
+	assert.Equal(t, usage{"gpt-4o": {Prompt: 160, Completion: 40}}, summary.usage)
+	cost, _ := cfg.cost(summary.usage)
+	assert.InDelta(t, (160*2.5+40*10)/1e6, cost, 1e-12)
+
+	got, err := locales.Read(cfg.LocalePath("de"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"one": "Übersetzt", "two": "Übersetzt"}, got, "finished batches are saved")
+}
+
+func TestValidate_MaxCostNeedsPrices(t *testing.T) {
+	cfg := SyncConfig{OpenAIModel: "gpt-4o", Fallbacks: []string{"local-llm"}, MaxCost: 5, Pricing: DefaultPrices}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `max cost needs the price of model "local-llm"`)
+	assert.NotContains(t, err.Error(), `"gpt-4o"`)
+}
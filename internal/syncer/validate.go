@@ -0,0 +1,204 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/llm"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/messages"
+	"github.com/you/nogodey/internal/styleguide"
+)
+
+// ErrInvalidConfig wraps the errors of Validate when a command refuses to
+// run with the configuration.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Validate checks the configuration before any file is read or any API
+// call is made. Every problem is reported, joined with errors.Join.
+func (c SyncConfig) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.BatchSize < 1 {
+		fail("batch size must be at least 1, got %d (%s)", c.BatchSize, c.source("batch_size"))
+	}
+	if c.MaxRetries < 1 {
+		fail("max retries must be at least 1, got %d (%s)", c.MaxRetries, c.source("max_retries"))
+	}
+
+	if c.MaxTokens < 0 {
+		fail("max tokens must not be negative, got %d (%s)", c.MaxTokens, c.source("max_tokens"))
+	}
+	if c.MaxCost < 0 {
+		fail("max cost must not be negative, got %v (%s)", c.MaxCost, c.source("max_cost"))
+	}
+	if c.MaxCost > 0 {
+		for _, model := range c.models() {
+			if _, ok := c.Pricing[model]; !ok {
+				fail("max cost needs the price of model %q, set pricing.%s in the project file (%s)", model, model, c.source("max_cost"))
+			}
+		}
+	}
+
+	if c.MaxReferences < 0 {
+		fail("max references must not be negative, got %d (%s)", c.MaxReferences, c.source("max_references"))
+	}
+	for _, l := range c.ReferenceLocales {
+		if _, err := locales.Parse(l); err != nil {
+			fail("reference locales: %v (%s)", err, c.source("reference_locales"))
+		}
+	}
+
+	if _, err := c.Checks(); err != nil {
+		fail("%v (%s)", err, c.source("disabled_checks"))
+	}
+
+	if len(c.Locales) == 0 {
+		fail("no locales to sync (%s)", c.source("locales"))
+	}
+	seen := make(map[string]bool, len(c.Locales))
+	for _, l := range c.Locales {
+		switch _, err := locales.Parse(l); {
+		case err != nil:
+			fail("%v (%s)", err, c.source("locales"))
+		case seen[l]:
+			fail("locale %q listed twice (%s)", l, c.source("locales"))
+		}
+		seen[l] = true
+	}
+
+	if _, err := locales.Parse(c.Source()); err != nil {
+		fail("source locale: %v (%s)", err, c.source("source_locale"))
+	}
+	if c.PivotLocale != "" {
+		if _, err := locales.Parse(c.PivotLocale); err != nil {
+			fail("pivot locale: %v (%s)", err, c.source("pivot_locale"))
+		}
+	}
+	for locale, opts := range c.LocaleOptions {
+		if opts.Pivot != "" {
+			if _, err := locales.Parse(opts.Pivot); err != nil {
+				fail("localeOptions.%s.pivot: %v (%s)", locale, err, c.source("locale_options"))
+			}
+		}
+		if _, err := c.PostProcessor(locale); err != nil {
+			fail("localeOptions.%s.postProcess: %v (%s)", locale, err, c.source("locale_options"))
+		}
+		if opts.StyleGuide != "" {
+			if _, err := styleguide.Load(opts.StyleGuide); err != nil {
+				fail("localeOptions.%s.styleGuide: %v (%s)", locale, err, c.source("locale_options"))
+			}
+		}
+		if opts.Parent == "" {
+			continue
+		}
+		if _, err := locales.Parse(opts.Parent); err != nil {
+			fail("localeOptions.%s.parent: %v (%s)", locale, err, c.source("locale_options"))
+			continue
+		}
+		if c.hasParentCycle(locale) {
+			fail("localeOptions.%s.parent: inheritance cycle %s (%s)", locale, strings.Join(append([]string{locale}, c.Ancestors(locale)...), " → "), c.source("locale_options"))
+		}
+	}
+
+	provider := c.Provider
+	if provider == "" {
+		provider = DefaultProvider
+	}
+	if !slices.Contains(config.Providers, provider) {
+		fail("unknown provider %q, want one of %s (%s)", provider, strings.Join(config.Providers, ", "), c.source("provider"))
+	}
+	if provider == "openai" && c.OpenAIKey == "" && c.LLMMode != llm.ModeReplay {
+		fail("OPENAI_API_KEY not found in environment variables or .env file")
+	}
+	if provider == "exec" || slices.ContainsFunc(c.Fallbacks, func(f string) bool { return ParseModelRef(f, provider).Provider == "exec" }) {
+		if len(c.ExecCommand) == 0 {
+			fail("the exec provider needs exec.command in the project file (%s)", c.source("provider"))
+		} else if _, err := exec.LookPath(c.ExecCommand[0]); err != nil {
+			fail("exec.command: %v (%s)", err, c.source("exec_command"))
+		}
+	}
+	if err := validModel(c.OpenAIModel); err != nil {
+		fail("model: %v (%s)", err, c.source("model"))
+	}
+	for _, f := range c.Fallbacks {
+		if err := validModel(ParseModelRef(f, provider).Model); err != nil {
+			fail("fallbacks: %v (%s)", err, c.source("fallbacks"))
+		}
+	}
+	for locale, opts := range c.LocaleOptions {
+		if opts.Model == "" {
+			continue
+		}
+		if err := validModel(opts.Model); err != nil {
+			fail("localeOptions.%s.model: %v (%s)", locale, err, c.source("locale_options"))
+		}
+	}
+
+	if _, err := messages.ParseCollisionPolicy(string(c.CollisionPolicy)); c.CollisionPolicy != "" && err != nil {
+		fail("%v (%s)", err, c.source("on_collision"))
+	}
+
+	manifest := c.Manifest()
+	if info, err := os.Stat(manifest); err != nil {
+		fail("manifest %s not found, run 'nogodey build' first (%s)", manifest, c.source("manifest"))
+	} else if info.IsDir() {
+		fail("manifest %s is a directory (%s)", manifest, c.source("manifest"))
+	}
+	if info, err := os.Stat(c.LocalesDir); c.LocalesDir != "" && err == nil && !info.IsDir() {
+		fail("locales dir %s is not a directory (%s)", c.LocalesDir, c.source("locales_dir"))
+	}
+	if c.LocalePattern != "" {
+		switch {
+		case !strings.Contains(c.LocalePattern, "{locale}"):
+			fail("locale pattern %q must contain {locale} (%s)", c.LocalePattern, c.source("locale_pattern"))
+		case filepath.IsAbs(c.LocalePattern):
+			fail("locale pattern %q must be relative to the locales dir (%s)", c.LocalePattern, c.source("locale_pattern"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// hasParentCycle reports whether following parents from locale leads back
+// to locale itself.
+func (c SyncConfig) hasParentCycle(locale string) bool {
+	seen := map[string]bool{}
+	for p := c.ParentOf(locale); p != ""; p = c.ParentOf(p) {
+		if p == locale {
+			return true
+		}
+		if seen[p] {
+			return false
+		}
+		seen[p] = true
+	}
+	return false
+}
+
+// source describes where a setting came from for error messages.
+func (c SyncConfig) source(name string) string {
+	if s, ok := c.Sources[name]; ok {
+		return "from " + s
+	}
+	return "from config"
+}
+
+func validModel(model string) error {
+	switch {
+	case model == "":
+		return errors.New("must not be empty")
+	case strings.ContainsAny(model, " \t\n"):
+		return fmt.Errorf("%q must not contain whitespace", model)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package syncer
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/you/nogodey/cmd/nogodey/logger"
+	"github.com/you/nogodey/internal/llm"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/mask"
+	"github.com/you/nogodey/internal/messages"
+	"github.com/you/nogodey/internal/similarity"
+)
+
+// Verdict is the back-translation of one key and how close it comes to the
+// source text.
+type Verdict struct {
+	Key             string
+	Source          string
+	Translation     string
+	BackTranslation string
+	// Score is the chrF similarity of the back-translation and the source,
+	// from 0 to 1; tags and ICU arguments are left out.
+	Score float64
+	// Unscored is set when no back-translation passed the checks, so the
+	// key has no BackTranslation and a Score of 0.
+	Unscored bool
+}
+
+// Verify back-translates the translations of locale into the source
+// language and scores each against its source string, lowest score first.
+// A low score points at a key a reviewer should look at: the translation
+// may have lost or changed the meaning. Keys that could not be
+// back-translated come first, unscored. Nothing is written.
+func Verify(cfg SyncConfig, locale string) ([]Verdict, error) {
+	log := logger.New()
+	if locale == cfg.Source() {
+		return nil, fmt.Errorf("%s is the source locale", locale)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	lc := localeContext{locale: cfg.Source(), sourceLocale: locale}
+	if err := requireChat(cfg.modelChain(lc), "verify"); err != nil {
+		return nil, err
+	}
+	all, err := messages.Read(cfg.Manifest())
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	msgs, _, err := messages.Validate(all, cfg.CollisionPolicy)
+	if err != nil {
+		return nil, err
+	}
+	path := cfg.LocalePath(locale)
+	translations, err := locales.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading locale file %s: %w", path, err)
+	}
+
+	// The batch to back-translate carries the translations as its text.
+	var batch []Message
+	sources := make(map[string]string)
+	for _, m := range msgs {
+		if t, ok := translations[m.Key]; ok {
+			sources[m.Key] = m.Default
+			m.Default = t
+			batch = append(batch, m)
+		}
+	}
+
+	// Budgets are for the UI, not for back-translations.
+	back := cfg
+	back.KeyBudgets, back.LengthRatios = nil, nil
+	build := func(b []Message, lc localeContext) string { return buildBackTranslationPrompt(b, lc, locale, cfg) }
+	client := newClient(cfg, cfg.Provider)
+
+	var out []Verdict
+	for i := 0; i < len(batch); i += cfg.BatchSize {
+		part := batch[i:min(i+cfg.BatchSize, len(batch))]
+		log.Info("back-translating batch", "locale", locale, "batch", i/cfg.BatchSize+1, "total_batches", int(math.Ceil(float64(len(batch))/float64(cfg.BatchSize))))
+		backs, err := callWithRetries(log, client, part, build, lc, back)
+		if err != nil {
+			return out, fmt.Errorf("back-translating %s: %w", locale, err)
+		}
+		for _, m := range part {
+			bt, ok := backs[m.Key]
+			if !ok {
+				log.Warn("no back-translation passed the checks", "locale", locale, "key", m.Key)
+				out = append(out, Verdict{Key: m.Key, Source: sources[m.Key], Translation: m.Default, Unscored: true})
+				continue
+			}
+			out = append(out, Verdict{
+				Key:             m.Key,
+				Source:          sources[m.Key],
+				Translation:     m.Default,
+				BackTranslation: bt,
+				Score:           similarity.ChrF(mask.Strip(sources[m.Key]), mask.Strip(bt)),
+			})
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Verdict) int {
+		if a.Unscored != b.Unscored {
+			if a.Unscored {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Score, b.Score)
+	})
+	return out, nil
+}
+
+// buildBackTranslationPrompt asks for a literal translation of locale's
+// strings into the source language. A fluent, corrected rendering would
+// hide the mistakes the comparison is meant to find.
+func buildBackTranslationPrompt(batch []Message, lc localeContext, locale string, cfg SyncConfig) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Translate the \"text\" of these UI strings from %s (%s) back into %s (%s) as literally as possible. The result is compared with the original to find mistranslations, so do not correct, improve or guess at the intended meaning.\n\n", locales.DisplayName(locale), locale, locales.DisplayName(cfg.Source()), cfg.Source()))
+	writeData(&b, batch, lc, func(m Message) llm.DataItem { return llm.DataItem{Text: m.Default} })
+	return b.String()
+}
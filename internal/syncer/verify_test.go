@@ -0,0 +1,110 @@
+package syncer
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func TestVerify(t *testing.T) {
+	client := &recordingClient{reply: func(string) string {
+		return `{"save": "Save the changes", "delete": "Remove the profile", "hello": "Hello ⟦0⟧⟦1⟧⟦2⟧!"}`
+	}}
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		Locales:      []string{"de"},
+		BatchSize:    10,
+		MaxRetries:   1,
+		OpenAIKey:    "test",
+		OpenAIModel:  "test",
+		LocalesDir:   dir,
+		LengthRatios: DefaultLengthRatios,
+		Client:       client,
+		ManifestPath: writeManifest(t, dir, []Message{
+			{Key: "save", Default: "Save changes", Kind: "label"},
+			{Key: "delete", Default: "Delete your account"},
+			{Key: "hello", Default: "Hello <b>{name}</b>!"},
+			{Key: "new", Default: "Not translated yet"},
+		}),
+	}
+	translations := map[string]string{"save": "Änderungen speichern", "delete": "Konto löschen", "hello": "Hallo <b>{name}</b>!"}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), translations))
+	before, err := os.ReadFile(cfg.LocalePath("de"))
+	require.NoError(t, err)
+
+	verdicts, err := Verify(cfg, "de")
+	require.NoError(t, err)
+	require.Len(t, verdicts, 3, "keys without a translation are skipped")
+	assert.Equal(t, "delete", verdicts[0].Key, "lowest score first")
+	assert.Equal(t, "Konto löschen", verdicts[0].Translation)
+	assert.Equal(t, "Remove the profile", verdicts[0].BackTranslation)
+	assert.Equal(t, "hello", verdicts[2].Key)
+	assert.Equal(t, 1.0, verdicts[2].Score)
+
+	require.Len(t, client.prompts, 1)
+	assert.Contains(t, client.prompts[0], "from German (de) back into English (en) as literally as possible")
+	assert.Contains(t, client.prompts[0], `"save": {"text":"Änderungen speichern"}`, "no length budgets")
+	assert.False(t, strings.Contains(client.prompts[0], "Not translated yet"))
+
+	after, err := os.ReadFile(cfg.LocalePath("de"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	_, err = Verify(cfg, "en")
+	assert.ErrorContains(t, err, "source locale")
+}
+
+func TestVerify_ReportsUnscored(t *testing.T) {
+	// The back-translation of "hello" keeps dropping the placeholder.
+	client := &recordingClient{reply: func(string) string {
+		return `{"save": "Save the changes", "hello": "Hello!"}`
+	}}
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		Locales:      []string{"de"},
+		BatchSize:    10,
+		MaxRetries:   2,
+		OpenAIKey:    "test",
+		OpenAIModel:  "test",
+		LocalesDir:   dir,
+		Client:       client,
+		ManifestPath: writeManifest(t, dir, []Message{{Key: "save", Default: "Save changes"}, {Key: "hello", Default: "Hello {name}!"}}),
+	}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), map[string]string{"save": "Änderungen speichern", "hello": "Hallo {name}!"}))
+
+	verdicts, err := Verify(cfg, "de")
+	require.NoError(t, err)
+	require.Len(t, verdicts, 2)
+	assert.Equal(t, "hello", verdicts[0].Key, "unscored keys come first")
+	assert.True(t, verdicts[0].Unscored)
+	assert.Empty(t, verdicts[0].BackTranslation)
+	assert.False(t, verdicts[1].Unscored)
+}
+
+func TestVerify_NeedsChatModel(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		Locales:      []string{"de"},
+		BatchSize:    10,
+		MaxRetries:   1,
+		OpenAIKey:    "test",
+		OpenAIModel:  "test",
+		Provider:     "pseudo",
+		LocalesDir:   dir,
+		ManifestPath: writeManifest(t, dir, []Message{{Key: "save", Default: "Save"}}),
+	}
+	_, err := Verify(cfg, "de")
+	assert.ErrorContains(t, err, "verify needs a chat model, but provider pseudo only translates")
+	_, err = Audit(cfg, "de")
+	assert.ErrorContains(t, err, "audit needs a chat model")
+
+	cfg.Provider = ""
+	cfg.Fallbacks = []string{"exec/engine"}
+	cfg.ExecCommand = []string{os.Args[0]}
+	_, err = Verify(cfg, "de")
+	assert.ErrorContains(t, err, "provider exec only translates", "fallbacks count too")
+}
@@ -0,0 +1,73 @@
+package syncer
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/you/nogodey/internal/font"
+	"github.com/you/nogodey/internal/locales"
+	"github.com/you/nogodey/internal/mask"
+)
+
+// DefaultFontSize is the default text size of web browsers.
+const DefaultFontSize = 16
+
+// WidthIssue is a translation that renders wider than its key's pixel
+// budget and is likely to be truncated.
+type WidthIssue struct {
+	Locale string
+	Path   string
+	Key    string
+	Width  float64
+	Budget float64
+}
+
+func (w WidthIssue) String() string {
+	return fmt.Sprintf("%s: %s: %.0fpx wide, the budget is %.0fpx", w.Path, w.Key, w.Width, w.Budget)
+}
+
+// CheckWidths measures the translations of keys with a maxWidth budget in
+// the target locales and reports those wider than it. Argument values are
+// unknown and count as nothing, so keys with arguments need some slack in
+// their budget. Locale files that do not exist are skipped.
+func CheckWidths(cfg SyncConfig, targets []string, fonts []*font.Font) ([]WidthIssue, error) {
+	var keys []string
+	for _, key := range slices.Sorted(maps.Keys(cfg.KeyBudgets)) {
+		if cfg.KeyBudgets[key].MaxWidth > 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	var out []WidthIssue
+	for _, locale := range targets {
+		path := cfg.LocalePath(locale)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		translations, err := locales.Read(path)
+		if err != nil {
+			return out, fmt.Errorf("reading locale file %s: %w", path, err)
+		}
+		for _, key := range keys {
+			text, ok := translations[key]
+			if !ok {
+				continue
+			}
+			b := cfg.KeyBudgets[key]
+			size := b.FontSize
+			if size == 0 {
+				size = cfg.FontSize
+			}
+			width, err := font.Width(fonts, mask.Strip(text), size)
+			if err != nil {
+				return out, err
+			}
+			if width > b.MaxWidth {
+				out = append(out, WidthIssue{Locale: locale, Path: path, Key: key, Width: width, Budget: b.MaxWidth})
+			}
+		}
+	}
+	return out, nil
+}
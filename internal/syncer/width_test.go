@@ -0,0 +1,44 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/you/nogodey/internal/config"
+	"github.com/you/nogodey/internal/font"
+	"github.com/you/nogodey/internal/font/fonttest"
+	"github.com/you/nogodey/internal/locales"
+)
+
+func TestCheckWidths(t *testing.T) {
+	// Every glyph is half an em wide: 8px per character at 16px.
+	tables := fonttest.Metrics(1000, []uint16{500})
+	tables["cmap"] = fonttest.Cmap(fonttest.Chars("abcdefghijklmnopqrstuvwxyzäöüß "))
+	f, err := font.Parse(fonttest.SFNT(tables))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	cfg := SyncConfig{
+		LocalesDir: dir,
+		FontSize:   DefaultFontSize,
+		KeyBudgets: map[string]config.Budget{
+			"save":  {MaxWidth: 80},
+			"title": {MaxWidth: 100, FontSize: 24},
+			"body":  {MaxLength: 200},
+		},
+	}
+	require.NoError(t, locales.Write(cfg.LocalePath("de"), map[string]string{
+		"save":  "speichern",            // 72px
+		"title": "einstellungen",        // 156px at 24px
+		"body":  "ein sehr langer text", // no width budget
+	}))
+	require.NoError(t, locales.Write(cfg.LocalePath("fr"), map[string]string{"save": "<b>enregistrer</b> {n}"}))
+
+	issues, err := CheckWidths(cfg, []string{"de", "fr", "es"}, []*font.Font{f})
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, cfg.LocalePath("de")+": title: 156px wide, the budget is 100px", issues[0].String())
+	assert.Equal(t, "fr", issues[1].Locale)
+	assert.InDelta(t, 96, issues[1].Width, 1e-9, "tags and arguments are not measured")
+}
@@ -26,6 +26,9 @@ type Message = {
   readonly default: string
   readonly file: string
   readonly loc: {readonly line: number; readonly column: number}
+  // Where the string appears: 'text' for <Text> children, otherwise the
+  // attribute name. The sync command uses it to pick a length budget.
+  readonly kind: string
 }
 
 type TransformArgs = {
@@ -72,8 +75,8 @@ const buildKey = (filePath: string, text: string): string => {
   return slug(`${cleanPath}-${cleanText}`, {lower: true})
 }
 
-const recordMessage = (key: string, text: string, filePath: string, loc: Location): void => {
-  messages.push({key, default: text, file: filePath, loc})
+const recordMessage = (key: string, text: string, filePath: string, loc: Location, kind: string): void => {
+  messages.push({key, default: text, file: filePath, loc, kind})
 }
 
 const createLocation = (node: t.Node): Location => {
@@ -184,7 +187,7 @@ const plugin: Plugin = {
                       'recording text message'
                     )
 
-                    recordMessage(key, txt, filePath, loc)
+                    recordMessage(key, txt, filePath, loc, 'text')
                     transformCount++
 
                     const isIcu = ICU_PATTERN.test(txt)
@@ -241,7 +244,7 @@ const plugin: Plugin = {
                     'recording attribute message'
                   )
 
-                  recordMessage(key, txt, filePath, loc)
+                  recordMessage(key, txt, filePath, loc, node.name.name)
                   transformCount++
 
                   const isIcu = ICU_PATTERN.test(txt)